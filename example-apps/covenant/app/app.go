@@ -18,6 +18,8 @@ import (
 
 	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
 	covenant "github.com/cosmos/cosmos-academy/example-apps/covenant/x/covenant"
+	names "github.com/cosmos/cosmos-academy/example-apps/covenant/x/names"
+	slashing "github.com/cosmos/cosmos-academy/example-apps/covenant/x/slashing"
 )
 
 const (
@@ -30,11 +32,13 @@ type CovenantApp struct {
 	cdc *wire.Codec
 
 	// keys to access the substores
-	keyMain    *sdk.KVStoreKey
-	keyAccount *sdk.KVStoreKey
-	keyIBC     *sdk.KVStoreKey
-	keyStake   *sdk.KVStoreKey
-	keyCov     *sdk.KVStoreKey
+	keyMain     *sdk.KVStoreKey
+	keyAccount  *sdk.KVStoreKey
+	keyIBC      *sdk.KVStoreKey
+	keyStake    *sdk.KVStoreKey
+	keyCov      *sdk.KVStoreKey
+	keyNames    *sdk.KVStoreKey
+	keySlashing *sdk.KVStoreKey
 
 	// Manage getting and setting accounts
 	accountMapper       auth.AccountMapper
@@ -43,6 +47,8 @@ type CovenantApp struct {
 	ibcMapper           ibc.Mapper
 	stakeKeeper         stake.Keeper
 	covKeeper           covenant.Keeper
+	namesKeeper         names.Keeper
+	slashingKeeper      slashing.Keeper
 }
 
 func NewCovenantApp(logger log.Logger, db dbm.DB) *CovenantApp {
@@ -52,13 +58,15 @@ func NewCovenantApp(logger log.Logger, db dbm.DB) *CovenantApp {
 
 	// Create your application object.
 	var app = &CovenantApp{
-		BaseApp:    bam.NewBaseApp(appName, cdc, logger, db),
-		cdc:        cdc,
-		keyMain:    sdk.NewKVStoreKey("main"),
-		keyAccount: sdk.NewKVStoreKey("acc"),
-		keyIBC:     sdk.NewKVStoreKey("ibc"),
-		keyStake:   sdk.NewKVStoreKey("stake"),
-		keyCov:     sdk.NewKVStoreKey("covenant"),
+		BaseApp:     bam.NewBaseApp(appName, cdc, logger, db),
+		cdc:         cdc,
+		keyMain:     sdk.NewKVStoreKey("main"),
+		keyAccount:  sdk.NewKVStoreKey("acc"),
+		keyIBC:      sdk.NewKVStoreKey("ibc"),
+		keyStake:    sdk.NewKVStoreKey("stake"),
+		keyCov:      sdk.NewKVStoreKey("covenant"),
+		keyNames:    sdk.NewKVStoreKey("names"),
+		keySlashing: sdk.NewKVStoreKey("slashing"),
 	}
 
 	// Define the accountMapper.
@@ -72,19 +80,25 @@ func NewCovenantApp(logger log.Logger, db dbm.DB) *CovenantApp {
 	app.coinKeeper = bank.NewKeeper(app.accountMapper)
 	app.ibcMapper = ibc.NewMapper(app.cdc, app.keyIBC, app.RegisterCodespace(ibc.DefaultCodespace))
 	app.stakeKeeper = stake.NewKeeper(app.cdc, app.keyStake, app.coinKeeper, app.RegisterCodespace(stake.DefaultCodespace))
-	app.covKeeper = covenant.NewKeeper(app.cdc, app.keyCov, app.coinKeeper)
+	app.covKeeper = covenant.NewKeeper(app.cdc, app.keyCov, app.coinKeeper, app.stakeKeeper, app.RegisterCodespace(covenant.DefaultCodespace))
+	app.slashingKeeper = slashing.NewKeeper(app.cdc, app.keySlashing, app.stakeKeeper, app.coinKeeper, app.RegisterCodespace(slashing.DefaultCodespace))
+	app.namesKeeper = names.NewKeeper(app.keyNames, app.accountMapper, app.RegisterCodespace(names.DefaultCodespace))
 
 	// register message routes
 	app.Router().
 		AddRoute("auth", auth.NewHandler(app.accountMapper)).
 		AddRoute("bank", bank.NewHandler(app.coinKeeper)).
 		AddRoute("ibc", ibc.NewHandler(app.ibcMapper, app.coinKeeper)).
-		AddRoute("stake", stake.NewHandler(app.stakeKeeper)).
-		AddRoute("covenant", covenant.NewHandler(app.covKeeper))
+		AddRoute("stake", slashing.NewStakeHandler(app.slashingKeeper, stake.NewHandler(app.stakeKeeper))).
+		AddRoute("slashing", slashing.NewHandler(app.slashingKeeper)).
+		AddRoute("covenant", covenant.NewHandler(app.covKeeper)).
+		AddRoute("names", names.NewHandler(app.namesKeeper))
 
 	// Initialize BaseApp.
 	app.SetInitChainer(app.initChainer)
-	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyStake, app.keyCov)
+	app.SetBeginBlocker(app.BeginBlocker)
+	app.SetEndBlocker(app.EndBlocker)
+	app.MountStoresIAVL(app.keyMain, app.keyAccount, app.keyIBC, app.keyStake, app.keyCov, app.keyNames, app.keySlashing)
 	app.SetAnteHandler(auth.NewAnteHandler(app.accountMapper, app.feeCollectionKeeper))
 	err := app.LoadLatestVersion(app.keyMain)
 	if err != nil {
@@ -102,6 +116,8 @@ func MakeCodec() *wire.Codec {
 	stake.RegisterWire(cdc)
 	ibc.RegisterWire(cdc)
 	covenant.RegisterWire(cdc)
+	names.RegisterWire(cdc)
+	slashing.RegisterWire(cdc)
 
 	// register custom AppAccount
 	cdc.RegisterInterface((*auth.Account)(nil), nil)
@@ -120,6 +136,7 @@ func (app *CovenantApp) initChainer(ctx sdk.Context, req abci.RequestInitChain)
 		// return sdk.ErrGenesisParse("").TraceCause(err, "")
 	}
 
+	var addrs []sdk.Address
 	for _, gacc := range genesisState.Accounts {
 		acc, err := gacc.ToAppAccount()
 		if err != nil {
@@ -127,10 +144,55 @@ func (app *CovenantApp) initChainer(ctx sdk.Context, req abci.RequestInitChain)
 			//	return sdk.ErrGenesisParse("").TraceCause(err, "")
 		}
 		app.accountMapper.SetAccount(ctx, acc)
+		addrs = append(addrs, acc.Address)
+	}
+
+	// index the names of genesis accounts
+	err = app.namesKeeper.InitGenesis(ctx, addrs)
+	if err != nil {
+		panic(err) // TODO https://github.com/cosmos/cosmos-sdk/issues/468
+	}
+
+	// load the initial stake information
+	stakeData := stake.DefaultGenesisState()
+	if genesisState.StakeData != nil {
+		stakeData = *genesisState.StakeData
+	}
+	stake.InitGenesis(ctx, app.stakeKeeper, stakeData)
+
+	slashingData := slashing.DefaultGenesis()
+	if genesisState.SlashingData != nil {
+		slashingData = *genesisState.SlashingData
+	}
+	err = app.slashingKeeper.InitGenesis(ctx, slashingData, stakeData.Validators)
+	if err != nil {
+		panic(err) // TODO https://github.com/cosmos/cosmos-sdk/issues/468
+	}
+
+	err = app.covKeeper.InitGenesis(ctx, genesisState.Covenant)
+	if err != nil {
+		panic(err) // TODO https://github.com/cosmos/cosmos-sdk/issues/468
 	}
 	return abci.ResponseInitChain{}
 }
 
+// application updates every begin block
+func (app *CovenantApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+	app.slashingKeeper.BeginBlocker(ctx, req)
+	covenant.BeginBlocker(ctx, app.covKeeper)
+	return abci.ResponseBeginBlock{}
+}
+
+// application updates every end block
+func (app *CovenantApp) EndBlocker(ctx sdk.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+	covenant.EndBlocker(ctx, app.covKeeper)
+	validatorUpdates := stake.EndBlocker(ctx, app.stakeKeeper)
+	validatorUpdates = app.slashingKeeper.EndBlocker(ctx, validatorUpdates)
+	return abci.ResponseEndBlock{
+		ValidatorUpdates: validatorUpdates,
+	}
+}
+
 // Custom logic for state export
 func (app *CovenantApp) ExportAppStateJSON() (appState json.RawMessage, err error) {
 	ctx := app.NewContext(true, abci.Header{})
@@ -142,13 +204,21 @@ func (app *CovenantApp) ExportAppStateJSON() (appState json.RawMessage, err erro
 			Address: acc.GetAddress(),
 			Coins:   acc.GetCoins(),
 		}
+		if named, ok := acc.(*types.AppAccount); ok {
+			account.Name = named.Name
+		}
 		accounts = append(accounts, account)
 		return false
 	}
 	app.accountMapper.IterateAccounts(ctx, appendAccount)
 
+	stakeData := stake.WriteGenesis(ctx, app.stakeKeeper)
+	slashingData := app.slashingKeeper.WriteGenesis(ctx)
 	genState := types.GenesisState{
-		Accounts: accounts,
+		Accounts:     accounts,
+		StakeData:    &stakeData,
+		SlashingData: &slashingData,
+		Covenant:     app.covKeeper.WriteGenesis(ctx),
 	}
 	return wire.MarshalJSONIndent(app.cdc, genState)
 }
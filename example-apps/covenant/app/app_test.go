@@ -10,10 +10,13 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
+	slashing "github.com/cosmos/cosmos-academy/example-apps/covenant/x/slashing"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/cosmos/cosmos-sdk/x/ibc"
+	"github.com/cosmos/cosmos-sdk/x/stake"
 
 	abci "github.com/tendermint/abci/types"
 	crypto "github.com/tendermint/go-crypto"
@@ -208,6 +211,133 @@ func TestGenesis(t *testing.T) {
 	assert.Equal(t, acc, res1)
 }
 
+func TestStakeGenesis(t *testing.T) {
+	bapp := newCovenantApp()
+
+	// addr1 holds the bond denom of the default stake params
+	stakeData := stake.DefaultGenesisState()
+	genesisState := types.GenesisState{
+		Accounts: []*types.GenesisAccount{{
+			Address: addr1,
+			Coins:   sdk.Coins{{stakeData.Params.BondDenom, 1000}},
+		}},
+		StakeData: &stakeData,
+	}
+	stateBytes, err := wire.MarshalJSONIndent(bapp.cdc, genesisState)
+	require.Nil(t, err)
+	vals := []abci.Validator{}
+	bapp.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: stateBytes})
+	bapp.Commit()
+
+	// Bonding a new validator reaches Tendermint from EndBlock
+	bond := sdk.Coin{stakeData.Params.BondDenom, 500}
+	createValidator := stake.NewMsgCreateValidator(addr1, priv1.PubKey(), bond, stake.Description{Moniker: "val1"})
+	tx := genTx(createValidator, []int64{0}, priv1)
+	bapp.BeginBlock(abci.RequestBeginBlock{})
+	res := bapp.Deliver(tx)
+	require.Equal(t, sdk.ABCICodeOK, res.Code, res.Log)
+	endRes := bapp.EndBlock(abci.RequestEndBlock{})
+	bapp.Commit()
+	require.Equal(t, 1, len(endRes.ValidatorUpdates))
+	CheckBalance(t, bapp, addr1, "500"+stakeData.Params.BondDenom)
+
+	// The validator is part of the exported state
+	appState, err := bapp.ExportAppStateJSON()
+	require.Nil(t, err)
+	var exported types.GenesisState
+	err = bapp.cdc.UnmarshalJSON(appState, &exported)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(exported.StakeData.Validators))
+}
+
+func TestSlashingDowntime(t *testing.T) {
+	bapp := newCovenantApp()
+
+	stakeData := stake.DefaultGenesisState()
+	slashingData := slashing.Genesis{Params: slashing.Params{
+		SignedBlocksWindow:    10,
+		MinSignedPerWindow:    5,
+		DowntimeJailDuration:  5,
+		SlashFractionDowntime: 1000,
+	}}
+	genesisState := types.GenesisState{
+		Accounts: []*types.GenesisAccount{{
+			Address: addr1,
+			Coins:   sdk.Coins{{stakeData.Params.BondDenom, 1000}},
+		}},
+		StakeData:    &stakeData,
+		SlashingData: &slashingData,
+	}
+	stateBytes, err := wire.MarshalJSONIndent(bapp.cdc, genesisState)
+	require.Nil(t, err)
+	vals := []abci.Validator{}
+	bapp.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: stateBytes})
+	bapp.Commit()
+
+	bond := sdk.Coin{stakeData.Params.BondDenom, 500}
+	createValidator := stake.NewMsgCreateValidator(addr1, priv1.PubKey(), bond, stake.Description{Moniker: "val1"})
+	SignCheckDeliver(t, bapp, createValidator, []int64{0}, true, priv1)
+	bapp.Commit()
+
+	// The validator misses every block of the window and is jailed
+	absent := abci.SigningValidator{
+		Validator:       abci.Validator{Address: priv1.PubKey().Address()},
+		SignedLastBlock: false,
+	}
+	var endRes abci.ResponseEndBlock
+	for height := int64(1); height <= 11; height++ {
+		bapp.BeginBlock(abci.RequestBeginBlock{
+			Header:     abci.Header{Height: height},
+			Validators: []abci.SigningValidator{absent},
+		})
+		endRes = bapp.EndBlock(abci.RequestEndBlock{Height: height})
+		bapp.Commit()
+	}
+	require.Equal(t, 1, len(endRes.ValidatorUpdates))
+	require.Equal(t, int64(0), endRes.ValidatorUpdates[0].Power)
+
+	// 10% of the self-bond was unbonded and burned
+	ctx := bapp.BaseApp.NewContext(true, abci.Header{})
+	delegation, found := bapp.stakeKeeper.GetDelegation(ctx, addr1, addr1)
+	require.True(t, found)
+	require.Equal(t, int64(450), delegation.Shares.Evaluate())
+	CheckBalance(t, bapp, addr1, "500"+stakeData.Params.BondDenom)
+
+	// The validator can only be unjailed after its jail time
+	unjail := slashing.MsgUnjail{ValidatorAddr: addr1}
+	SignCheckDeliver(t, bapp, unjail, []int64{1}, false, priv1)
+	bapp.Commit()
+
+	// The jail survives an export and import of the chain state
+	appState, err := bapp.ExportAppStateJSON()
+	require.Nil(t, err)
+	var exported types.GenesisState
+	err = bapp.cdc.UnmarshalJSON(appState, &exported)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(exported.SlashingData.Validators))
+	require.True(t, exported.SlashingData.Validators[0].SigningInfo.Jailed)
+	require.Equal(t, int64(16), exported.SlashingData.Validators[0].SigningInfo.JailedUntil)
+
+	imported := newCovenantApp()
+	imported.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: appState})
+	imported.Commit()
+	reexported, err := imported.ExportAppStateJSON()
+	require.Nil(t, err)
+	require.Equal(t, string(appState), string(reexported))
+	imported.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	endRes = imported.EndBlock(abci.RequestEndBlock{Height: 1})
+	require.Equal(t, 1, len(endRes.ValidatorUpdates))
+	require.Equal(t, int64(0), endRes.ValidatorUpdates[0].Power)
+
+	bapp.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 16}})
+	res := bapp.Deliver(genTx(unjail, []int64{2}, priv1))
+	require.Equal(t, sdk.ABCICodeOK, res.Code, res.Log)
+	endRes = bapp.EndBlock(abci.RequestEndBlock{Height: 16})
+	bapp.Commit()
+	require.Equal(t, 1, len(endRes.ValidatorUpdates))
+	require.Equal(t, int64(450), endRes.ValidatorUpdates[0].Power)
+}
+
 func TestMsgChangePubKey(t *testing.T) {
 
 	bapp := newCovenantApp()
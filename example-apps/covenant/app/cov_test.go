@@ -1,15 +1,21 @@
 package app
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
 	cov "github.com/cosmos/cosmos-academy/example-apps/covenant/x/covenant"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/stake"
 
 	abci "github.com/tendermint/abci/types"
+	cmn "github.com/tendermint/tmlibs/common"
 )
 
 func TestCovenant(t *testing.T) {
@@ -68,3 +74,771 @@ func TestCovenant(t *testing.T) {
 	CheckBalance(t, app, addr1, "5000foocoin")
 	CheckBalance(t, app, addr2, "1000foocoin")
 }
+
+func TestChannel(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }, {
+        "address": "%s",
+        "coins": []
+      }]
+    }`, addr1.String(), coinDenom, addr2.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// A channel without a challenge window is rejected
+	createChan := cov.MsgCreateChannel{
+		Sender:   addr1,
+		Receiver: addr2,
+		Amount:   sdk.Coins{{coinDenom, 1000}},
+	}
+	require.NotNil(t, createChan.ValidateBasic())
+
+	// addr1 opens a channel to addr2 that can be paid out 2 blocks after closing
+	createChan.ChallengePeriod = 2
+	res := SignCheckDeliver(t, app, createChan, []int64{0}, true, priv1)
+	var chanID int64
+	app.cdc.UnmarshalBinary(res.Data, &chanID)
+	require.Equal(t, int64(0), chanID)
+	app.Commit()
+	CheckBalance(t, app, addr1, "9000foocoin")
+
+	// Nothing to finalize while the channel is open
+	finalize := cov.MsgFinalizeChannel{Signer: addr2, ChannelID: chanID}
+	SignCheckDeliver(t, app, finalize, []int64{0}, false, priv2)
+
+	// Off-chain, addr1 has paid 250foocoin in total by its second voucher
+	voucher2 := cov.Voucher{ChannelID: chanID, Amount: sdk.Coins{{coinDenom, 250}}, Nonce: 2}
+
+	// A voucher signed by anyone but the sender is rejected
+	forged := cov.MsgCloseChannel{
+		Closer:    addr2,
+		Voucher:   voucher2,
+		PubKey:    priv2.PubKey(),
+		Signature: priv2.Sign(voucher2.GetSignBytes(chainID)),
+	}
+	SignCheckDeliver(t, app, forged, []int64{1}, false, priv2)
+
+	// So is a voucher signed for another chain
+	forged.PubKey = priv1.PubKey()
+	forged.Signature = priv1.Sign(voucher2.GetSignBytes("other-chain"))
+	SignCheckDeliver(t, app, forged, []int64{2}, false, priv2)
+
+	// addr2 closes with the latest voucher
+	closeChan := cov.MsgCloseChannel{
+		Closer:    addr2,
+		Voucher:   voucher2,
+		PubKey:    priv1.PubKey(),
+		Signature: priv1.Sign(voucher2.GetSignBytes(chainID)),
+	}
+	SignCheckDeliver(t, app, closeChan, []int64{3}, true, priv2)
+	app.Commit()
+
+	// Inside the window, addr1 can't replace the claim with a newer voucher paying less
+	stale := cov.Voucher{ChannelID: chanID, Amount: sdk.Coins{{coinDenom, 100}}, Nonce: 3}
+	staleClose := cov.MsgCloseChannel{
+		Closer:    addr1,
+		Voucher:   stale,
+		PubKey:    priv1.PubKey(),
+		Signature: priv1.Sign(stale.GetSignBytes(chainID)),
+	}
+	res = SignCheckDeliver(t, app, staleClose, []int64{1}, false, priv1)
+	require.Equal(t, sdk.ToABCICode(cov.DefaultCodespace, cov.CodeInvalidVoucher), res.Code, res.Log)
+
+	// Nor can the channel be paid out before the window is over
+	SignCheckDeliver(t, app, finalize, []int64{4}, false, priv2)
+
+	// addr2 still gets to submit a newer voucher paying more
+	voucher3 := cov.Voucher{ChannelID: chanID, Amount: sdk.Coins{{coinDenom, 300}}, Nonce: 3}
+	closeChan.Voucher = voucher3
+	closeChan.Signature = priv1.Sign(voucher3.GetSignBytes(chainID))
+	SignCheckDeliver(t, app, closeChan, []int64{5}, true, priv2)
+	app.Commit()
+
+	// Once the window is over, the channel is paid out
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 2}})
+	res = app.Deliver(genTx(finalize, []int64{6}, priv2))
+	require.Equal(t, sdk.ABCICodeOK, res.Code, res.Log)
+	app.EndBlock(abci.RequestEndBlock{Height: 2})
+	app.Commit()
+	SignCheckDeliver(t, app, finalize, []int64{7}, false, priv2)
+
+	CheckBalance(t, app, addr1, "9700foocoin")
+	CheckBalance(t, app, addr2, "300foocoin")
+}
+
+func TestCovenantCondition(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }, {
+        "address": "%s",
+        "coins": []
+      }]
+    }`, addr1.String(), coinDenom, addr4.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// Settlement needs either the secret, or addr1 and addr4 signing together
+	secret := []byte("secret")
+	hash := sha256.Sum256(secret)
+	condition := cov.Condition{
+		Type: cov.CondOr,
+		Children: []cov.Condition{
+			{Type: cov.CondPreimage, Hash: hash[:]},
+			{Type: cov.CondSignedBy, Addresses: []sdk.Address{addr1, addr4}, Threshold: 2},
+		},
+	}
+
+	// Malformed conditions are rejected
+	badCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:  []sdk.Address{addr1},
+		Receivers: []sdk.Address{addr2},
+		Amount:    sdk.Coins{{coinDenom, 1000}},
+		Condition: &cov.Condition{Type: cov.CondSignedBy, Addresses: []sdk.Address{addr4}, Threshold: 2},
+	}
+	require.NotNil(t, badCov.ValidateBasic())
+
+	createCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:  []sdk.Address{addr1},
+		Receivers: []sdk.Address{addr2},
+		Amount:    sdk.Coins{{coinDenom, 1000}},
+		Condition: &condition,
+	}
+	SignCheckDeliver(t, app, createCov, []int64{0}, true, priv1)
+	SignCheckDeliver(t, app, createCov, []int64{1}, true, priv1)
+	app.Commit()
+
+	// The settler alone does not satisfy the condition
+	settle := cov.MsgSettleCovenant{CovID: 0, Settler: addr1, Receiver: addr2}
+	SignCheckDeliver(t, app, settle, []int64{2}, false, priv1)
+
+	// Revealing the secret does
+	settle.Witness = cov.Witness{Preimages: []cmn.HexBytes{secret}}
+	SignCheckDeliver(t, app, settle, []int64{3}, true, priv1)
+	app.Commit()
+
+	// So does a co-signature of addr4
+	settle = cov.MsgSettleCovenant{CovID: 1, Settler: addr1, Receiver: addr2,
+		Witness: cov.Witness{Signers: []sdk.Address{addr4}},
+	}
+	SignCheckDeliver(t, app, settle, []int64{4, 0}, true, priv1, priv4)
+	app.Commit()
+
+	CheckBalance(t, app, addr1, "8000foocoin")
+	CheckBalance(t, app, addr2, "2000foocoin")
+}
+
+func TestSubscription(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }]
+    }`, addr1.String(), coinDenom)
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// 100foocoin every block out of 250foocoin, and a second one to cancel
+	createSub := cov.MsgCreateSubscription{
+		Sender:   addr1,
+		Receiver: addr2,
+		Amount:   sdk.Coins{{coinDenom, 100}},
+		Period:   1,
+		Deposit:  sdk.Coins{{coinDenom, 250}},
+	}
+	SignCheckDeliver(t, app, createSub, []int64{0}, true, priv1)
+	res := SignCheckDeliver(t, app, createSub, []int64{1}, true, priv1)
+	var subID int64
+	app.cdc.UnmarshalBinary(res.Data, &subID)
+	require.Equal(t, int64(1), subID)
+	app.Commit()
+	CheckBalance(t, app, addr1, "9500foocoin")
+
+	cancelSub := cov.MsgCancelSubscription{Sender: addr1, SubscriptionID: subID}
+	SignCheckDeliver(t, app, cancelSub, []int64{2}, true, priv1)
+	app.Commit()
+	SignCheckDeliver(t, app, cancelSub, []int64{3}, false, priv1)
+	app.Commit()
+	CheckBalance(t, app, addr1, "9750foocoin")
+
+	// Two payments go through, then the remaining 50foocoin are refunded
+	for height := int64(1); height <= 3; height++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height}})
+		app.EndBlock(abci.RequestEndBlock{Height: height})
+		app.Commit()
+	}
+	CheckBalance(t, app, addr1, "9800foocoin")
+	CheckBalance(t, app, addr2, "200foocoin")
+}
+
+func TestSubscriptionPaymentCap(t *testing.T) {
+	app := newCovenantApp()
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [{"denom": "foocoin", "amount": 10000}]
+      }],
+      "covenant": {
+        "params": {
+          "max_payments_per_block": 1
+        }
+      }
+    }`, addr1.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// Two subscriptions fall due at every height, each for two payments
+	createSub := cov.MsgCreateSubscription{
+		Sender:   addr1,
+		Receiver: addr2,
+		Amount:   sdk.Coins{{"foocoin", 100}},
+		Period:   1,
+		Deposit:  sdk.Coins{{"foocoin", 200}},
+	}
+	SignCheckDeliver(t, app, createSub, []int64{0}, true, priv1)
+	SignCheckDeliver(t, app, createSub, []int64{1}, true, priv1)
+	app.Commit()
+
+	// One payment is made per block, the late ones carry over
+	for height := int64(1); height <= 4; height++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height}})
+		app.EndBlock(abci.RequestEndBlock{Height: height})
+		app.Commit()
+		CheckBalance(t, app, addr2, fmt.Sprintf("%dfoocoin", 100*height))
+	}
+	CheckBalance(t, app, addr1, "9600foocoin")
+}
+
+func TestSettlementWindow(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }]
+    }`, addr1.String(), coinDenom)
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// A window that closes before it opens is rejected
+	createCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:  []sdk.Address{addr1},
+		Receivers: []sdk.Address{addr2},
+		Amount:    sdk.Coins{{coinDenom, 1000}},
+		NotBefore: 10,
+		NotAfter:  5,
+	}
+	require.NotNil(t, createCov.ValidateBasic())
+
+	// The covenant can't be settled during its cooling-off period
+	createCov.NotAfter = 0
+	SignCheckDeliver(t, app, createCov, []int64{0}, true, priv1)
+	app.Commit()
+
+	settleCov := cov.MsgSettleCovenant{CovID: 0, Settler: addr1, Receiver: addr2}
+	res := SignCheckDeliver(t, app, settleCov, []int64{1}, false, priv1)
+	require.Equal(t, sdk.ToABCICode(cov.DefaultCodespace, cov.CodeOutsideSettlementWindow), res.Code, res.Log)
+	CheckBalance(t, app, addr1, "9000foocoin")
+}
+
+func TestReclaimCovenant(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }]
+    }`, addr1.String(), coinDenom)
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	deliverAt := func(height int64, msg sdk.Msg, seq int64, expPass bool) sdk.Result {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height}})
+		res := app.Deliver(genTx(msg, []int64{seq}, priv1))
+		if expPass {
+			require.Equal(t, sdk.ABCICodeOK, res.Code, res.Log)
+		} else {
+			require.NotEqual(t, sdk.ABCICodeOK, res.Code, res.Log)
+		}
+		app.EndBlock(abci.RequestEndBlock{Height: height})
+		app.Commit()
+		return res
+	}
+
+	// A covenant whose settlement window is already over is rejected
+	createCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:  []sdk.Address{addr2},
+		Receivers: []sdk.Address{addr2},
+		Amount:    sdk.Coins{{coinDenom, 1000}},
+		NotAfter:  5,
+	}
+	res := deliverAt(5, createCov, 0, false)
+	require.Equal(t, sdk.ToABCICode(cov.DefaultCodespace, cov.CodeOutsideSettlementWindow), res.Code, res.Log)
+
+	createCov.NotAfter = 7
+	deliverAt(5, createCov, 1, true)
+	CheckBalance(t, app, addr1, "9000foocoin")
+
+	// The sender can't take the escrow back while it can still be settled
+	reclaim := cov.MsgReclaimCovenant{Sender: addr1, CovID: 0}
+	res = deliverAt(7, reclaim, 2, false)
+	require.Equal(t, sdk.ToABCICode(cov.DefaultCodespace, cov.CodeOutsideSettlementWindow), res.Code, res.Log)
+
+	// Once the window is over, it goes back to the sender, once
+	deliverAt(8, reclaim, 3, true)
+	CheckBalance(t, app, addr1, "10000foocoin")
+	deliverAt(9, reclaim, 4, false)
+}
+
+func TestWeightedSettlers(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }, {
+        "address": "%s",
+        "coins": []
+      }]
+    }`, addr1.String(), coinDenom, addr4.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// addr1 is the lead party, but needs addr4 to agree
+	createCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:  []sdk.Address{addr1, addr4},
+		Receivers: []sdk.Address{addr2, addr3},
+		Amount:    sdk.Coins{{coinDenom, 1000}},
+		Weights:   []int64{2, 1},
+		Threshold: 3,
+	}
+	// A settler listed twice would count its weight twice
+	dupCov := createCov
+	dupCov.Settlers = []sdk.Address{addr1, addr1}
+	require.NotNil(t, dupCov.ValidateBasic())
+	// Weights that would wrap around their total are rejected
+	overflowCov := createCov
+	overflowCov.Weights = []int64{math.MaxInt64, 2}
+	require.NotNil(t, overflowCov.ValidateBasic())
+
+	SignCheckDeliver(t, app, createCov, []int64{0}, true, priv1)
+	app.Commit()
+
+	// Neither approval alone reaches the threshold, and each reports what is missing
+	var remaining int64
+	settleCov := cov.MsgSettleCovenant{CovID: 0, Settler: addr1, Receiver: addr2}
+	res := SignCheckDeliver(t, app, settleCov, []int64{1}, true, priv1)
+	app.cdc.UnmarshalBinary(res.Data, &remaining)
+	require.Equal(t, int64(1), remaining)
+	app.Commit()
+	settleCov = cov.MsgSettleCovenant{CovID: 0, Settler: addr4, Receiver: addr3}
+	res = SignCheckDeliver(t, app, settleCov, []int64{0}, true, priv4)
+	app.cdc.UnmarshalBinary(res.Data, &remaining)
+	require.Equal(t, int64(2), remaining)
+	app.Commit()
+	CheckBalance(t, app, addr1, "9000foocoin")
+
+	// addr4 changes its mind, and together they reach the threshold
+	settleCov = cov.MsgSettleCovenant{CovID: 0, Settler: addr4, Receiver: addr2}
+	res = SignCheckDeliver(t, app, settleCov, []int64{1}, true, priv4)
+	app.cdc.UnmarshalBinary(res.Data, &remaining)
+	require.Equal(t, int64(0), remaining)
+	app.Commit()
+	CheckBalance(t, app, addr2, "1000foocoin")
+}
+
+func TestSettlerDelegation(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }, {
+        "address": "%s",
+        "coins": []
+      }]
+    }`, addr1.String(), coinDenom, addr4.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	createCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:  []sdk.Address{addr1},
+		Receivers: []sdk.Address{addr2},
+		Amount:    sdk.Coins{{coinDenom, 1000}},
+	}
+	SignCheckDeliver(t, app, createCov, []int64{0}, true, priv1)
+	SignCheckDeliver(t, app, createCov, []int64{1}, true, priv1)
+
+	// This one also needs addr1's own signature
+	condCov := createCov
+	condCov.Condition = &cov.Condition{Type: cov.CondSignedBy, Addresses: []sdk.Address{addr1}, Threshold: 1}
+	SignCheckDeliver(t, app, condCov, []int64{2}, true, priv1)
+	app.Commit()
+
+	// addr4 can't settle for addr1 without a delegation
+	settleCov := cov.MsgSettleCovenant{CovID: 0, Settler: addr4, Receiver: addr2, OnBehalfOf: addr1}
+	SignCheckDeliver(t, app, settleCov, []int64{0}, false, priv4)
+
+	// addr1 goes on leave and delegates all of its covenants to addr4
+	delegate := cov.MsgDelegateSettler{Settler: addr1, Delegate: addr4, CovID: cov.AllCovenants, Until: 10}
+	SignCheckDeliver(t, app, delegate, []int64{3}, true, priv1)
+	app.Commit()
+	SignCheckDeliver(t, app, settleCov, []int64{1}, true, priv4)
+	app.Commit()
+	CheckBalance(t, app, addr2, "1000foocoin")
+
+	// The delegation approves on addr1's behalf, but doesn't sign for it
+	condSettle := settleCov
+	condSettle.CovID = 2
+	res := SignCheckDeliver(t, app, condSettle, []int64{2}, false, priv4)
+	require.Equal(t, sdk.ToABCICode(cov.DefaultCodespace, cov.CodeConditionNotMet), res.Code, res.Log)
+
+	// Once revoked, the delegate can't settle anymore
+	revoke := cov.MsgRevokeSettlerDelegation{Settler: addr1, CovID: cov.AllCovenants}
+	SignCheckDeliver(t, app, revoke, []int64{4}, true, priv1)
+	app.Commit()
+	settleCov.CovID = 1
+	SignCheckDeliver(t, app, settleCov, []int64{3}, false, priv4)
+	SignCheckDeliver(t, app, revoke, []int64{5}, false, priv1)
+}
+
+func TestCreateCovenantBatch(t *testing.T) {
+	app := newCovenantApp()
+	coinDenom := "foocoin"
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {
+            "denom": "%s",
+            "amount": 10000
+          }
+        ]
+      }]
+    }`, addr1.String(), coinDenom)
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	payroll := cov.MsgCreateCovenants{Sender: addr1}
+	for _, receiver := range []sdk.Address{addr2, addr3, addr4} {
+		payroll.Covenants = append(payroll.Covenants, cov.MsgCreateCovenant{Sender: addr1,
+			Settlers:  []sdk.Address{addr1},
+			Receivers: []sdk.Address{receiver},
+			Amount:    sdk.Coins{{coinDenom, 1000}},
+		})
+	}
+
+	// Covenants of someone else can't be slipped into the batch
+	bad := cov.MsgCreateCovenants{Sender: addr1, Covenants: append([]cov.MsgCreateCovenant{}, payroll.Covenants...)}
+	bad.Covenants[1].Sender = addr2
+	require.NotNil(t, bad.ValidateBasic())
+
+	res := SignCheckDeliver(t, app, payroll, []int64{0}, true, priv1)
+	var ids []int64
+	app.cdc.UnmarshalBinary(res.Data, &ids)
+	require.Equal(t, []int64{0, 1, 2}, ids)
+	app.Commit()
+	CheckBalance(t, app, addr1, "7000foocoin")
+
+	// The whole batch fails if the sender can't fund all of it
+	for i := 0; i < 5; i++ {
+		payroll.Covenants = append(payroll.Covenants, payroll.Covenants[0])
+	}
+	SignCheckDeliver(t, app, payroll, []int64{1}, false, priv1)
+	app.Commit()
+	CheckBalance(t, app, addr1, "7000foocoin")
+}
+
+func TestCovenantKeyMigration(t *testing.T) {
+	app := newCovenantApp()
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": []
+      }]
+    }`, addr1.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+
+	// Store two covenants the way the chain did before the upgrade, and one
+	// under a malformed key. A chain that old has no params either.
+	ctx := app.BaseApp.NewContext(false, abci.Header{})
+	store := ctx.KVStore(app.keyCov)
+	store.Delete(cov.ParamsKey)
+	for _, id := range []string{"2", "10", "x"} {
+		bz, _ := app.cdc.MarshalBinary(cov.Covenant{
+			Settlers:  []sdk.Address{addr1},
+			Receivers: []sdk.Address{addr2},
+			Amount:    sdk.Coins{{"foocoin", 100}},
+		})
+		store.Set([]byte("arrays:covenants:"+id), bz)
+	}
+	app.Commit()
+
+	// The first block migrates them
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	app.EndBlock(abci.RequestEndBlock{Height: 1})
+	app.Commit()
+
+	// Binary keys iterate in id order
+	ctx = app.BaseApp.NewContext(true, abci.Header{})
+	store = ctx.KVStore(app.keyCov)
+	require.Nil(t, store.Get([]byte("arrays:covenants:10")))
+	require.NotNil(t, store.Get([]byte("arrays:covenants:x")))
+	iter := store.Iterator(cov.CovenantKey(0), cov.CovenantKey(100))
+	var keys [][]byte
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	iter.Close()
+	require.Equal(t, [][]byte{cov.CovenantKey(2), cov.CovenantKey(10)}, keys)
+
+	settleCov := cov.MsgSettleCovenant{CovID: 10, Settler: addr1, Receiver: addr2}
+	SignCheckDeliver(t, app, settleCov, []int64{0}, true, priv1)
+	app.Commit()
+	CheckBalance(t, app, addr2, "100foocoin")
+}
+
+func TestCovenantParams(t *testing.T) {
+	app := newCovenantApp()
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": [
+          {"denom": "barcoin", "amount": 100},
+          {"denom": "foocoin", "amount": 10000}
+        ]
+      }, {
+        "address": "%s",
+        "coins": []
+      }],
+      "covenant": {
+        "params": {
+          "authority": "%s",
+          "max_settlers": 1,
+          "min_escrow": [{"denom": "foocoin", "amount": 100}],
+          "allowed_denoms": ["foocoin"],
+          "fee_rate": 100
+        }
+      }
+    }`, addr1.String(), addr2.String(), addr1.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// Too many settlers, too little escrow and a denomination that isn't allowed
+	createCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:  []sdk.Address{addr1, addr2},
+		Receivers: []sdk.Address{addr2},
+		Amount:    sdk.Coins{{"foocoin", 1000}},
+	}
+	SignCheckDeliver(t, app, createCov, []int64{0}, false, priv1)
+	createCov.Settlers = []sdk.Address{addr1}
+	createCov.Amount = sdk.Coins{{"foocoin", 50}}
+	SignCheckDeliver(t, app, createCov, []int64{1}, false, priv1)
+	createCov.Amount = sdk.Coins{{"barcoin", 100}, {"foocoin", 1000}}
+	SignCheckDeliver(t, app, createCov, []int64{2}, false, priv1)
+
+	// A valid covenant pays a 1% fee on top of the escrow
+	createCov.Amount = sdk.Coins{{"foocoin", 1000}}
+	SignCheckDeliver(t, app, createCov, []int64{3}, true, priv1)
+	app.Commit()
+	CheckBalance(t, app, addr1, "100barcoin,8990foocoin")
+
+	// Channels and subscriptions follow the same params
+	createChan := cov.MsgCreateChannel{Sender: addr1, Receiver: addr2,
+		Amount: sdk.Coins{{"barcoin", 100}}, ChallengePeriod: 1}
+	SignCheckDeliver(t, app, createChan, []int64{4}, false, priv1)
+	createSub := cov.MsgCreateSubscription{Sender: addr1, Receiver: addr2,
+		Amount: sdk.Coins{{"foocoin", 10}}, Period: 1, Deposit: sdk.Coins{{"foocoin", 50}}}
+	SignCheckDeliver(t, app, createSub, []int64{5}, false, priv1)
+	createChan.Amount = sdk.Coins{{"foocoin", 1000}}
+	SignCheckDeliver(t, app, createChan, []int64{6}, true, priv1)
+	app.Commit()
+	CheckBalance(t, app, addr1, "100barcoin,7980foocoin")
+
+	// Only the authority can change the params, and it can't give them up
+	changeParams := cov.MsgChangeParams{Authority: addr2, Params: cov.Params{Authority: addr2, MaxSettlers: 2}}
+	SignCheckDeliver(t, app, changeParams, []int64{0}, false, priv2)
+	changeParams = cov.MsgChangeParams{Authority: addr1, Params: cov.Params{MaxSettlers: 2}}
+	require.NotNil(t, changeParams.ValidateBasic())
+	changeParams = cov.MsgChangeParams{Authority: addr1, Params: cov.Params{Authority: addr1, MaxSettlers: 2}}
+	SignCheckDeliver(t, app, changeParams, []int64{7}, true, priv1)
+	app.Commit()
+
+	createCov.Settlers = []sdk.Address{addr1, addr2}
+	SignCheckDeliver(t, app, createCov, []int64{8}, true, priv1)
+	app.Commit()
+	CheckBalance(t, app, addr1, "100barcoin,6980foocoin")
+}
+
+func TestClaimParamsAuthority(t *testing.T) {
+	app := newCovenantApp()
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "address": "%s",
+        "coins": []
+      }, {
+        "address": "%s",
+        "coins": []
+      }]
+    }`, addr1.String(), addr2.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+
+	// Without an authority, the first change sets it
+	changeParams := cov.MsgChangeParams{Authority: addr1, Params: cov.Params{Authority: addr1, MaxSettlers: 2}}
+	SignCheckDeliver(t, app, changeParams, []int64{0}, true, priv1)
+	app.Commit()
+
+	// Only once
+	changeParams = cov.MsgChangeParams{Authority: addr2, Params: cov.Params{Authority: addr2}}
+	SignCheckDeliver(t, app, changeParams, []int64{0}, false, priv2)
+	app.Commit()
+
+	ctx := app.BaseApp.NewContext(true, abci.Header{})
+	params := app.covKeeper.GetParams(ctx)
+	require.Equal(t, addr1, params.Authority)
+	require.Equal(t, int64(2), params.MaxSettlers)
+}
+
+func TestDelegatedCovenant(t *testing.T) {
+	app := newCovenantApp()
+	stakeData := stake.DefaultGenesisState()
+	bondDenom := stakeData.Params.BondDenom
+	genesisState := types.GenesisState{
+		Accounts: []*types.GenesisAccount{{
+			Address: addr1,
+			Coins:   sdk.Coins{{"foocoin", 500}, {bondDenom, 10000}},
+		}, {
+			Address: addr2,
+			Coins:   sdk.Coins{{"foocoin", 1}},
+		}},
+		StakeData: &stakeData,
+		Covenant:  cov.Genesis{Params: cov.Params{UnbondingPeriod: 5}},
+	}
+	stateBytes, err := wire.MarshalJSONIndent(app.cdc, genesisState)
+	require.Nil(t, err)
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: stateBytes})
+	app.Commit()
+
+	bond := sdk.Coin{bondDenom, 1000}
+	createValidator := stake.NewMsgCreateValidator(addr1, priv1.PubKey(), bond, stake.Description{Moniker: "val1"})
+	SignCheckDeliver(t, app, createValidator, []int64{0}, true, priv1)
+	app.Commit()
+
+	// The escrow of a delegated covenant is bonded to the validator
+	createCov := cov.MsgCreateCovenant{Sender: addr1,
+		Settlers:       []sdk.Address{addr1},
+		Receivers:      []sdk.Address{addr2},
+		Amount:         sdk.Coins{{bondDenom, 500}},
+		Validator:      addr1,
+		RewardReceiver: addr3,
+	}
+	SignCheckDeliver(t, app, createCov, []int64{1}, true, priv1)
+	app.Commit()
+	CheckBalance(t, app, addr1, fmt.Sprintf("500foocoin,8500%s", bondDenom))
+	ctx := app.BaseApp.NewContext(true, abci.Header{})
+	delegation, found := app.stakeKeeper.GetDelegation(ctx, cov.EscrowAddress(0), addr1)
+	require.True(t, found)
+	require.Equal(t, int64(500), delegation.Shares.Evaluate())
+
+	// Only the bond denomination can be delegated
+	createCov.Amount = sdk.Coins{{"foocoin", 500}}
+	SignCheckDeliver(t, app, createCov, []int64{2}, false, priv1)
+
+	// Without a validator there are no rewards to receive
+	undelegated := createCov
+	undelegated.Validator = nil
+	require.NotNil(t, undelegated.ValidateBasic())
+
+	// Settling unbonds the escrow and pays it out after the unbonding period
+	settleCov := cov.MsgSettleCovenant{CovID: 0, Settler: addr1, Receiver: addr2}
+	SignCheckDeliver(t, app, settleCov, []int64{3}, true, priv1)
+	app.Commit()
+	ctx = app.BaseApp.NewContext(true, abci.Header{})
+	_, found = app.stakeKeeper.GetDelegation(ctx, cov.EscrowAddress(0), addr1)
+	require.False(t, found)
+
+	for height := int64(1); height <= 5; height++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: height}})
+		app.EndBlock(abci.RequestEndBlock{Height: height})
+		app.Commit()
+		if height == 4 {
+			CheckBalance(t, app, addr2, "1foocoin")
+		}
+	}
+	CheckBalance(t, app, addr2, fmt.Sprintf("1foocoin,500%s", bondDenom))
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
+	slashing "github.com/cosmos/cosmos-academy/example-apps/covenant/x/slashing"
+)
+
+// CovenantAppInit is the server's default app init with stake genesis added
+func CovenantAppInit() server.AppInit {
+	appInit := server.DefaultAppInit
+	appInit.AppGenState = CovenantAppGenState
+	return appInit
+}
+
+// CovenantAppGenState creates the genesis accounts of the default app init,
+// stake genesis that bonds the coin those accounts hold and default slashing
+// params.
+func CovenantAppGenState(cdc *wire.Codec, appGenTxs []json.RawMessage) (appState json.RawMessage, err error) {
+	simpleState, err := server.SimpleAppGenState(cdc, appGenTxs)
+	if err != nil {
+		return nil, err
+	}
+	var genesisState types.GenesisState
+	err = cdc.UnmarshalJSON(simpleState, &genesisState)
+	if err != nil {
+		return nil, err
+	}
+
+	stakeData := stake.DefaultGenesisState()
+	if len(genesisState.Accounts) != 0 && len(genesisState.Accounts[0].Coins) != 0 {
+		stakeData.Params.BondDenom = genesisState.Accounts[0].Coins[0].Denom
+	}
+	genesisState.StakeData = &stakeData
+	slashingData := slashing.DefaultGenesis()
+	genesisState.SlashingData = &slashingData
+	return wire.MarshalJSONIndent(cdc, genesisState)
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/x/names"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	abci "github.com/tendermint/abci/types"
+)
+
+func TestNames(t *testing.T) {
+	app := newCovenantApp()
+	// addr1 is named alice at genesis
+	genesisState := fmt.Sprintf(`{
+      "accounts": [{
+        "name": "alice",
+        "address": "%s",
+        "coins": []
+      }, {
+        "address": "%s",
+        "coins": []
+      }]
+    }`, addr1.String(), addr2.String())
+
+	vals := []abci.Validator{}
+	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: []byte(genesisState)})
+	app.Commit()
+	CheckName(t, app, "alice", addr1)
+
+	// Names are unique
+	SignCheckDeliver(t, app, names.MsgClaimName{Owner: addr2, Name: "alice"}, []int64{0}, false, priv2)
+	SignCheckDeliver(t, app, names.MsgClaimName{Owner: addr2, Name: "bob"}, []int64{1}, true, priv2)
+	app.Commit()
+	CheckName(t, app, "bob", addr2)
+
+	// Only the owner can transfer a name
+	transfer := names.MsgTransferName{Owner: addr2, Name: "alice", NewOwner: addr1}
+	SignCheckDeliver(t, app, transfer, []int64{2}, false, priv2)
+
+	// Taking over alice releases bob
+	transfer = names.MsgTransferName{Owner: addr1, Name: "alice", NewOwner: addr2}
+	SignCheckDeliver(t, app, transfer, []int64{0}, true, priv1)
+	app.Commit()
+	CheckName(t, app, "alice", addr2)
+	CheckName(t, app, "bob", nil)
+
+	SignCheckDeliver(t, app, names.MsgClaimName{Owner: addr1, Name: "bob"}, []int64{1}, true, priv1)
+	app.Commit()
+	CheckName(t, app, "bob", addr1)
+
+	ctx := app.BaseApp.NewContext(false, abci.Header{})
+	acc := app.accountMapper.GetAccount(ctx, addr2).(*types.AppAccount)
+	require.Equal(t, "alice", acc.Name)
+}
+
+func CheckName(t *testing.T, bapp *CovenantApp, name string, addrExpected sdk.Address) {
+	ctxDeliver := bapp.BaseApp.NewContext(false, abci.Header{})
+	require.Equal(t, addrExpected, bapp.namesKeeper.GetAddress(ctxDeliver, name))
+}
@@ -14,6 +14,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/tx"
 
 	covenantcmd "github.com/cosmos/cosmos-academy/example-apps/covenant/x/covenant/client/cli"
+	namescmd "github.com/cosmos/cosmos-academy/example-apps/covenant/x/names/client/cli"
+	slashingcmd "github.com/cosmos/cosmos-academy/example-apps/covenant/x/slashing/client/cli"
 
 	"github.com/cosmos/cosmos-sdk/version"
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
@@ -54,6 +56,7 @@ func main() {
 	rootCmd.AddCommand(
 		client.GetCommands(
 			authcmd.GetAccountCmd("acc", cdc, types.GetAccountDecoder(cdc)),
+			slashingcmd.GetSigningInfoCmd("slashing", cdc),
 		)...)
 
 	rootCmd.AddCommand(
@@ -65,6 +68,7 @@ func main() {
 			stakecmd.GetCmdEditValidator(cdc),
 			stakecmd.GetCmdDelegate(cdc),
 			stakecmd.GetCmdUnbond(cdc),
+			slashingcmd.UnjailTxCmd(cdc),
 		)...)
 
 	// add proxy, version and key info
@@ -79,7 +83,30 @@ func main() {
 	rootCmd.AddCommand(
 		client.PostCommands(
 			covenantcmd.CreateCovenantTxCmd(cdc),
+			covenantcmd.CreateCovenantsTxCmd(cdc),
 			covenantcmd.SettleCovenantTxCmd(cdc),
+			covenantcmd.ReclaimCovenantTxCmd(cdc),
+			covenantcmd.CreateChannelTxCmd(cdc),
+			covenantcmd.CloseChannelTxCmd(cdc),
+			covenantcmd.FinalizeChannelTxCmd(cdc),
+			covenantcmd.SignVoucherCmd(cdc),
+			covenantcmd.CreateSubscriptionTxCmd(cdc),
+			covenantcmd.CancelSubscriptionTxCmd(cdc),
+			covenantcmd.DelegateSettlerTxCmd(cdc),
+			covenantcmd.RevokeSettlerDelegationTxCmd(cdc),
+			covenantcmd.SignTxCmd(cdc),
+			covenantcmd.BroadcastTxCmd(cdc),
+			covenantcmd.ChangeParamsTxCmd(cdc),
+			namescmd.ClaimNameTxCmd(cdc),
+			namescmd.TransferNameTxCmd("names", cdc),
+		)...,
+	)
+
+	rootCmd.AddCommand(
+		client.GetCommands(
+			covenantcmd.GetDelegationCmd("covenant", cdc),
+			covenantcmd.GetParamsCmd("covenant", cdc),
+			namescmd.GetAddressCmd("names", cdc),
 		)...,
 	)
 
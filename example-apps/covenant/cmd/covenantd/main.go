@@ -25,7 +25,7 @@ func main() {
 		PersistentPreRunE: server.PersistentPreRunEFn(ctx),
 	}
 
-	server.AddCommands(ctx, cdc, rootCmd, server.DefaultAppInit,
+	server.AddCommands(ctx, cdc, rootCmd, app.CovenantAppInit(),
 		server.ConstructAppCreator(newApp, "covenantcoin"),
 		server.ConstructAppExporter(exportAppState, "covenantcoin"))
 
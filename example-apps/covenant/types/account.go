@@ -4,6 +4,10 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+
+	covenant "github.com/cosmos/cosmos-academy/example-apps/covenant/x/covenant"
+	slashing "github.com/cosmos/cosmos-academy/example-apps/covenant/x/slashing"
 )
 
 var _ auth.Account = (*AppAccount)(nil)
@@ -41,7 +45,10 @@ func GetAccountDecoder(cdc *wire.Codec) auth.AccountDecoder {
 
 // State to Unmarshal
 type GenesisState struct {
-	Accounts []*GenesisAccount `json:"accounts"`
+	Accounts     []*GenesisAccount   `json:"accounts"`
+	StakeData    *stake.GenesisState `json:"stake"`    // stake defaults if omitted
+	SlashingData *slashing.Genesis   `json:"slashing"` // slashing defaults if omitted
+	Covenant     covenant.Genesis    `json:"covenant"`
 }
 
 // GenesisAccount doesn't need pubkey or sequence
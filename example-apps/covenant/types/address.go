@@ -0,0 +1,38 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/tmlibs/bech32"
+)
+
+// Bech32PrefixAddr is the human readable part of addresses on this chain
+const Bech32PrefixAddr = "covaddr"
+
+// Bech32ifyAddress encodes an address as bech32 with the chain's prefix
+func Bech32ifyAddress(addr sdk.Address) (string, error) {
+	return bech32.ConvertAndEncode(Bech32PrefixAddr, addr)
+}
+
+// MustBech32ifyAddress panics on failure, for output of known-good addresses
+func MustBech32ifyAddress(addr sdk.Address) string {
+	str, err := Bech32ifyAddress(addr)
+	if err != nil {
+		panic(err)
+	}
+	return str
+}
+
+// GetAddressBech32 decodes a bech32 address, checking its checksum and that
+// it was made for this chain
+func GetAddressBech32(str string) (sdk.Address, error) {
+	hrp, bz, err := bech32.DecodeAndConvert(str)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != Bech32PrefixAddr {
+		return nil, fmt.Errorf("invalid bech32 prefix %s, addresses on this chain start with %s", hrp, Bech32PrefixAddr)
+	}
+	return sdk.Address(bz), nil
+}
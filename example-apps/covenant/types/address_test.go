@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	crypto "github.com/tendermint/go-crypto"
+	"github.com/tendermint/tmlibs/bech32"
+)
+
+func TestBech32Address(t *testing.T) {
+	addr := sdk.Address(crypto.GenPrivKeyEd25519().PubKey().Address())
+
+	// Round trip
+	str, err := Bech32ifyAddress(addr)
+	require.Nil(t, err)
+	require.Equal(t, Bech32PrefixAddr, str[:len(Bech32PrefixAddr)])
+	decoded, err := GetAddressBech32(str)
+	require.Nil(t, err)
+	require.Equal(t, addr, decoded)
+	require.Equal(t, str, MustBech32ifyAddress(addr))
+
+	// An address made for another chain is rejected
+	other, err := bech32.ConvertAndEncode("cosmosaccaddr", addr)
+	require.Nil(t, err)
+	_, err = GetAddressBech32(other)
+	require.NotNil(t, err)
+
+	// So is a typo, caught by the checksum
+	last := str[len(str)-1]
+	typo := byte('q')
+	if last == typo {
+		typo = 'p'
+	}
+	_, err = GetAddressBech32(str[:len(str)-1] + string(typo))
+	require.NotNil(t, err)
+
+	// And anything that is not bech32 at all
+	_, err = GetAddressBech32(addr.String())
+	require.NotNil(t, err)
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	covenant "github.com/cosmos/cosmos-academy/example-apps/covenant/x/covenant"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagFile = "file"
+)
+
+// covenantRow is one covenant of a batch file
+type covenantRow struct {
+	Settlers  []string `json:"settlers"`
+	Receivers []string `json:"receivers"`
+	Amount    string   `json:"amount"`
+}
+
+// CreateCovenantsTxCmd creates every covenant of a CSV or JSON file in a
+// single signed tx.
+//
+// A CSV file has the columns settlers, receivers and amount, with addresses
+// or names separated by semicolons:
+//
+//	settlers,receivers,amount
+//	covaddr1qy...;alice,covaddr1xz...,"100foocoin,5barcoin"
+//
+// A JSON file holds a list of objects with the same fields:
+//
+//	[{"settlers": ["covaddr1qy..."], "receivers": ["alice"], "amount": "100foocoin"}]
+func CreateCovenantsTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create_covenants",
+		Short: "Create many Covenants from a CSV or JSON file in one transaction",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			sender, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			file := viper.GetString(flagFile)
+			if len(file) == 0 {
+				return fmt.Errorf("specify the CSV or JSON file of covenants with --file")
+			}
+			rows, err := readCovenantRows(file)
+			if err != nil {
+				return err
+			}
+
+			msg := covenant.MsgCreateCovenants{Sender: sender}
+			for i, row := range rows {
+				cmsg, err := row.toMsg(ctx, sender)
+				if err != nil {
+					return fmt.Errorf("covenant %d of %s: %v", i+1, file, err)
+				}
+				msg.Covenants = append(msg.Covenants, cmsg)
+			}
+
+			res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			var ids []int64
+			err = cdc.UnmarshalBinary(res.DeliverTx.Data, &ids)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				fmt.Printf("Covenant created with id: %d\n", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String(flagFile, "", "CSV or JSON file with the covenants to create")
+	return cmd
+}
+
+func readCovenantRows(file string) ([]covenantRow, error) {
+	if strings.ToLower(filepath.Ext(file)) == ".json" {
+		bz, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var rows []covenantRow
+		err = json.Unmarshal(bz, &rows)
+		return rows, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var rows []covenantRow
+	for i, record := range records {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("line %d of %s: need the columns settlers, receivers and amount", i+1, file)
+		}
+		if i == 0 && strings.TrimSpace(record[0]) == "settlers" {
+			continue
+		}
+		rows = append(rows, covenantRow{
+			Settlers:  strings.Split(record[0], ";"),
+			Receivers: strings.Split(record[1], ";"),
+			Amount:    record[2],
+		})
+	}
+	return rows, nil
+}
+
+func (row covenantRow) toMsg(ctx context.CoreContext, sender sdk.Address) (covenant.MsgCreateCovenant, error) {
+	settlers, err := parseAddresses(ctx, row.Settlers)
+	if err != nil {
+		return covenant.MsgCreateCovenant{}, err
+	}
+	receivers, err := parseAddresses(ctx, row.Receivers)
+	if err != nil {
+		return covenant.MsgCreateCovenant{}, err
+	}
+	amount, err := sdk.ParseCoins(strings.TrimSpace(row.Amount))
+	if err != nil {
+		return covenant.MsgCreateCovenant{}, err
+	}
+	if len(settlers) == 0 || len(receivers) == 0 || amount.IsZero() {
+		return covenant.MsgCreateCovenant{}, fmt.Errorf("need settlers, receivers and an amount")
+	}
+	return covenant.MsgCreateCovenant{
+		Sender:    sender,
+		Settlers:  settlers,
+		Receivers: receivers,
+		Amount:    amount,
+	}, nil
+}
+
+func parseAddresses(ctx context.CoreContext, strs []string) ([]sdk.Address, error) {
+	var addrs []sdk.Address
+	for _, str := range strs {
+		str = strings.TrimSpace(str)
+		if len(str) == 0 {
+			continue
+		}
+		addr, err := parseAddress(ctx, str)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
@@ -1,35 +1,72 @@
 package cli
 
 import (
-	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"strconv"
 	"strings"
 
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
 	covenant "github.com/cosmos/cosmos-academy/example-apps/covenant/x/covenant"
+	namescmd "github.com/cosmos/cosmos-academy/example-apps/covenant/x/names/client/cli"
 	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/keys"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	crypto "github.com/tendermint/go-crypto"
 )
 
 const (
-	flagSettlers  = "settlers"
-	flagReceivers = "receivers"
-	flagAmount    = "amount"
-	flagCovID     = "covid"
-	flagReceiver  = "receiver"
+	flagSettlers        = "settlers"
+	flagReceivers       = "receivers"
+	flagAmount          = "amount"
+	flagCovID           = "covid"
+	flagReceiver        = "receiver"
+	flagChannelID       = "channel"
+	flagChallengePeriod = "challenge-period"
+	flagNonce           = "nonce"
+	flagVoucher         = "voucher"
+	flagCondition       = "condition"
+	flagWitness         = "witness"
+	flagPeriod          = "period"
+	flagDeposit         = "deposit"
+	flagSubscriptionID  = "subscription"
+	flagNotBefore       = "not-before"
+	flagNotAfter        = "not-after"
+	flagWeights         = "weights"
+	flagThreshold       = "threshold"
+	flagDelegate        = "delegate"
+	flagUntil           = "until"
+	flagOnBehalfOf      = "on-behalf-of"
+	flagSettler         = "settler"
+	flagParams          = "params"
+	flagNewAuthority    = "new-authority"
+	flagValidator       = "validator"
+	flagRewardReceiver  = "reward-receiver"
 )
 
+// namesStoreName is the store names are looked up in
+const namesStoreName = "names"
+
+// signedVoucher is what sign_voucher hands to the channel receiver
+type signedVoucher struct {
+	Voucher   covenant.Voucher `json:"voucher"`
+	PubKey    crypto.PubKey    `json:"pub_key"`
+	Signature crypto.Signature `json:"signature"`
+}
+
 func CreateCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create_covenant",
 		Short: "Create a new Covenant",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
-			sender, err := ctx.GetFromAddress()
+			sender, err := getFromAddress(ctx)
 			if err != nil {
 				return err
 			}
@@ -42,11 +79,11 @@ func CreateCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
 			settlersStrs := strings.Split(settlersString, ",")
 			var settlers []sdk.Address
 			for _, settler := range settlersStrs {
-				settlerBytes, err := hex.DecodeString(settler)
+				settlerAddr, err := parseAddress(ctx, settler)
 				if err != nil {
 					return err
 				}
-				settlers = append(settlers, sdk.Address(settlerBytes))
+				settlers = append(settlers, settlerAddr)
 			}
 
 			receiversString := viper.GetString(flagReceivers)
@@ -57,11 +94,11 @@ func CreateCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
 			receiverStrs := strings.Split(receiversString, ",")
 			var receivers []sdk.Address
 			for _, receiver := range receiverStrs {
-				receiverBytes, err := hex.DecodeString(receiver)
+				receiverAddr, err := parseAddress(ctx, receiver)
 				if err != nil {
 					return err
 				}
-				receivers = append(receivers, sdk.Address(receiverBytes))
+				receivers = append(receivers, receiverAddr)
 			}
 
 			amountString := viper.GetString(flagAmount)
@@ -74,7 +111,52 @@ func CreateCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
 				return err
 			}
 
-			msg := covenant.MsgCreateCovenant{sender, settlers, receivers, amount}
+			msg := covenant.MsgCreateCovenant{
+				Sender:    sender,
+				Settlers:  settlers,
+				Receivers: receivers,
+				Amount:    amount,
+				NotBefore: viper.GetInt64(flagNotBefore),
+				NotAfter:  viper.GetInt64(flagNotAfter),
+				Threshold: viper.GetInt64(flagThreshold),
+			}
+			weightsString := strings.TrimSpace(viper.GetString(flagWeights))
+			if len(weightsString) != 0 {
+				for _, w := range strings.Split(weightsString, ",") {
+					weight, err := strconv.ParseInt(w, 10, 64)
+					if err != nil {
+						return err
+					}
+					msg.Weights = append(msg.Weights, weight)
+				}
+			}
+			validatorString := viper.GetString(flagValidator)
+			if len(validatorString) != 0 {
+				msg.Validator, err = parseAddress(ctx, validatorString)
+				if err != nil {
+					return err
+				}
+				rewardReceiverString := viper.GetString(flagRewardReceiver)
+				if len(rewardReceiverString) == 0 {
+					return fmt.Errorf("specify who receives the staking rewards with --reward-receiver")
+				}
+				msg.RewardReceiver, err = parseAddress(ctx, rewardReceiverString)
+				if err != nil {
+					return err
+				}
+			}
+			conditionFile := viper.GetString(flagCondition)
+			if len(conditionFile) != 0 {
+				condition := new(covenant.Condition)
+				err = readJSONFile(conditionFile, condition)
+				if err != nil {
+					return err
+				}
+				msg.Condition = condition
+			}
+			if viper.GetBool(flagGenerateOnly) {
+				return generateTx(ctx, msg, cdc)
+			}
 			res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
 			if err != nil {
 				return err
@@ -88,9 +170,17 @@ func CreateCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
 			return nil
 		},
 	}
-	cmd.Flags().String(flagSettlers, "", "List of Settler Addresses")
-	cmd.Flags().String(flagReceivers, "", "List of Receiver Addresses")
+	cmd.Flags().String(flagSettlers, "", "List of Settler Addresses (bech32) or names")
+	cmd.Flags().String(flagReceivers, "", "List of Receiver Addresses (bech32) or names")
 	cmd.Flags().String(flagAmount, "", "Amount to put into covenant")
+	cmd.Flags().String(flagCondition, "", "JSON file with the settlement condition")
+	cmd.Flags().Int64(flagNotBefore, 0, "Earliest height the covenant can be settled at (0 for none)")
+	cmd.Flags().Int64(flagNotAfter, 0, "Latest height the covenant can be settled at (0 for none)")
+	cmd.Flags().String(flagWeights, "", "Comma separated settler weights, in the order of --settlers")
+	cmd.Flags().Int64(flagThreshold, 0, "Summed settler weight needed to settle (0 lets any settler settle)")
+	cmd.Flags().String(flagValidator, "", "Validator address (bech32) to keep the escrow delegated to")
+	cmd.Flags().String(flagRewardReceiver, "", "Address (bech32) or name receiving the staking rewards of a delegated escrow")
+	addGenerateOnlyFlags(cmd)
 	return cmd
 }
 
@@ -100,7 +190,7 @@ func SettleCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
 		Short: "Settle and existing Covenant",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
-			settler, err := ctx.GetFromAddress()
+			settler, err := getFromAddress(ctx)
 			if err != nil {
 				return err
 			}
@@ -109,28 +199,593 @@ func SettleCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
 			if len(receiverString) == 0 {
 				return fmt.Errorf("specify receiver address with --receiver")
 			}
-			receiverBytes, err := hex.DecodeString(receiverString)
+			receiver, err := parseAddress(ctx, receiverString)
 			if err != nil {
 				return err
 			}
 
-			receiver := sdk.Address(receiverBytes)
+			if !viper.IsSet(flagCovID) {
+				return fmt.Errorf("specify Covenant ID with --covid")
+			}
+			covID := viper.GetInt64(flagCovID)
+
+			msg := covenant.MsgSettleCovenant{
+				CovID:    covID,
+				Settler:  settler,
+				Receiver: receiver,
+			}
+			onBehalfOf := viper.GetString(flagOnBehalfOf)
+			if len(onBehalfOf) != 0 {
+				msg.OnBehalfOf, err = parseAddress(ctx, onBehalfOf)
+				if err != nil {
+					return err
+				}
+			}
+			witnessFile := viper.GetString(flagWitness)
+			if len(witnessFile) != 0 {
+				err = readJSONFile(witnessFile, &msg.Witness)
+				if err != nil {
+					return err
+				}
+			}
+			if viper.GetBool(flagGenerateOnly) {
+				return generateTx(ctx, msg, cdc)
+			}
+			res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			remaining := new(int64)
+			err = cdc.UnmarshalBinary(res.DeliverTx.Data, remaining)
+			if err != nil {
+				return err
+			}
+			if *remaining > 0 {
+				fmt.Printf("Settlement approved for covenant id: %d, receiver: %s, weight still needed: %d\n", covID, types.MustBech32ifyAddress(receiver), *remaining)
+				return nil
+			}
+			fmt.Printf("Covenant settled with id: %d, receiver: %s\n", covID, types.MustBech32ifyAddress(receiver))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagCovID, "", "Covenant ID")
+	cmd.Flags().String(flagReceiver, "", "Receiver Address (bech32) or name")
+	cmd.Flags().String(flagWitness, "", "JSON file with the witness for the covenant condition")
+	cmd.Flags().String(flagOnBehalfOf, "", "Settler address (bech32) or name to act for, as their delegate")
+	addGenerateOnlyFlags(cmd)
+	return cmd
+}
+
+// parseAddress reads an address flag given either as bech32 or as a name
+func parseAddress(ctx context.CoreContext, str string) (sdk.Address, error) {
+	return namescmd.ParseAddress(ctx, namesStoreName, str)
+}
+
+func readJSONFile(path string, o interface{}) error {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bz, o)
+}
+
+func ReclaimCovenantTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reclaim_covenant",
+		Short: "Take back the escrow of a covenant whose settlement window is over",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			sender, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
 
 			if !viper.IsSet(flagCovID) {
 				return fmt.Errorf("specify Covenant ID with --covid")
 			}
 			covID := viper.GetInt64(flagCovID)
 
-			msg := covenant.MsgSettleCovenant{covID, settler, receiver}
+			msg := covenant.MsgReclaimCovenant{sender, covID}
 			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Covenant settled with id: %d\n", covID)
+			fmt.Printf("Covenant reclaimed with id: %d\n", covID)
 			return nil
 		},
 	}
 	cmd.Flags().String(flagCovID, "", "Covenant ID")
-	cmd.Flags().String(flagReceiver, "", "Receiver Address")
+	return cmd
+}
+
+func CreateChannelTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create_channel",
+		Short: "Open a payment channel to a receiver",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			sender, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			receiverString := viper.GetString(flagReceiver)
+			if len(receiverString) == 0 {
+				return fmt.Errorf("specify receiver address with --receiver")
+			}
+			receiverAddr, err := parseAddress(ctx, receiverString)
+			if err != nil {
+				return err
+			}
+
+			amountString := viper.GetString(flagAmount)
+			if len(amountString) == 0 {
+				return fmt.Errorf("specify amount as comma separated list of coins with --amount")
+			}
+			amount, err := sdk.ParseCoins(amountString)
+			if err != nil {
+				return err
+			}
+
+			msg := covenant.MsgCreateChannel{
+				Sender:          sender,
+				Receiver:        receiverAddr,
+				Amount:          amount,
+				ChallengePeriod: viper.GetInt64(flagChallengePeriod),
+			}
+			res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			newChanID := new(int64)
+			err = cdc.UnmarshalBinary(res.DeliverTx.Data, newChanID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Channel created with id: %d\n", *newChanID)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagReceiver, "", "Receiver Address (bech32) or name")
+	cmd.Flags().String(flagAmount, "", "Amount to put into the channel")
+	cmd.Flags().Int64(flagChallengePeriod, 100, "Blocks to wait between closing and payout")
+	return cmd
+}
+
+// SignVoucherCmd signs a voucher with a local key, without touching the chain
+func SignVoucherCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign_voucher",
+		Short: "Sign an off-chain payment voucher for a channel",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper()
+
+			if !viper.IsSet(flagChannelID) {
+				return fmt.Errorf("specify Channel ID with --channel")
+			}
+			amount, err := sdk.ParseCoins(viper.GetString(flagAmount))
+			if err != nil {
+				return err
+			}
+			voucher := covenant.Voucher{
+				ChannelID: viper.GetInt64(flagChannelID),
+				Amount:    amount,
+				Nonce:     viper.GetInt64(flagNonce),
+			}
+
+			passphrase, err := ctx.GetPassphraseFromStdin(ctx.FromAddressName)
+			if err != nil {
+				return err
+			}
+			kb, err := keys.GetKeyBase()
+			if err != nil {
+				return err
+			}
+			if ctx.ChainID == "" {
+				return fmt.Errorf("specify the chain the voucher is for with --chain-id")
+			}
+			sig, pubKey, err := kb.Sign(ctx.FromAddressName, passphrase, voucher.GetSignBytes(ctx.ChainID))
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, signedVoucher{voucher, pubKey, sig})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagChannelID, "", "Channel ID")
+	cmd.Flags().String(flagAmount, "", "Cumulative amount paid through the channel")
+	cmd.Flags().Int64(flagNonce, 0, "Voucher nonce, must increase with every voucher")
+	return cmd
+}
+
+func CloseChannelTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "close_channel",
+		Short: "Close a channel, optionally with the latest signed voucher",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			closer, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			msg := covenant.MsgCloseChannel{Closer: closer}
+			voucherFile := viper.GetString(flagVoucher)
+			if len(voucherFile) != 0 {
+				bz, err := ioutil.ReadFile(voucherFile)
+				if err != nil {
+					return err
+				}
+				var sv signedVoucher
+				err = cdc.UnmarshalJSON(bz, &sv)
+				if err != nil {
+					return err
+				}
+				msg.Voucher = sv.Voucher
+				msg.PubKey = sv.PubKey
+				msg.Signature = sv.Signature
+			} else {
+				if !viper.IsSet(flagChannelID) {
+					return fmt.Errorf("specify --voucher, or the Channel ID with --channel")
+				}
+				msg.Voucher.ChannelID = viper.GetInt64(flagChannelID)
+			}
+
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Channel closing with id: %d\n", msg.Voucher.ChannelID)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagChannelID, "", "Channel ID")
+	cmd.Flags().String(flagVoucher, "", "File with a voucher from sign_voucher")
+	return cmd
+}
+
+func FinalizeChannelTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "finalize_channel",
+		Short: "Pay out a channel after its challenge window",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			signer, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			if !viper.IsSet(flagChannelID) {
+				return fmt.Errorf("specify Channel ID with --channel")
+			}
+			chanID := viper.GetInt64(flagChannelID)
+
+			msg := covenant.MsgFinalizeChannel{signer, chanID}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Channel finalized with id: %d\n", chanID)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagChannelID, "", "Channel ID")
+	return cmd
+}
+
+func CreateSubscriptionTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create_subscription",
+		Short: "Pay a receiver a fixed amount every period out of a deposit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			sender, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			receiverString := viper.GetString(flagReceiver)
+			if len(receiverString) == 0 {
+				return fmt.Errorf("specify receiver address with --receiver")
+			}
+			receiverAddr, err := parseAddress(ctx, receiverString)
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoins(viper.GetString(flagAmount))
+			if err != nil {
+				return err
+			}
+			deposit, err := sdk.ParseCoins(viper.GetString(flagDeposit))
+			if err != nil {
+				return err
+			}
+
+			msg := covenant.MsgCreateSubscription{
+				Sender:   sender,
+				Receiver: receiverAddr,
+				Amount:   amount,
+				Period:   viper.GetInt64(flagPeriod),
+				Deposit:  deposit,
+			}
+			res, err := ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			newSubID := new(int64)
+			err = cdc.UnmarshalBinary(res.DeliverTx.Data, newSubID)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Subscription created with id: %d\n", *newSubID)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagReceiver, "", "Receiver Address (bech32) or name")
+	cmd.Flags().String(flagAmount, "", "Amount paid every period")
+	cmd.Flags().Int64(flagPeriod, 0, "Blocks between payments")
+	cmd.Flags().String(flagDeposit, "", "Escrow the payments are taken from")
+	return cmd
+}
+
+func CancelSubscriptionTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel_subscription",
+		Short: "Stop a subscription and get back the remaining deposit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			sender, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			if !viper.IsSet(flagSubscriptionID) {
+				return fmt.Errorf("specify Subscription ID with --subscription")
+			}
+			subID := viper.GetInt64(flagSubscriptionID)
+
+			msg := covenant.MsgCancelSubscription{sender, subID}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Subscription cancelled with id: %d\n", subID)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagSubscriptionID, "", "Subscription ID")
+	return cmd
+}
+
+func DelegateSettlerTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delegate_settler",
+		Short: "Let another address settle in your place until a given height",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			settler, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			delegateString := viper.GetString(flagDelegate)
+			if len(delegateString) == 0 {
+				return fmt.Errorf("specify delegate address with --delegate")
+			}
+			delegateAddr, err := parseAddress(ctx, delegateString)
+			if err != nil {
+				return err
+			}
+			if !viper.IsSet(flagUntil) {
+				return fmt.Errorf("specify the last height of the delegation with --until")
+			}
+
+			msg := covenant.MsgDelegateSettler{
+				Settler:  settler,
+				Delegate: delegateAddr,
+				CovID:    viper.GetInt64(flagCovID),
+				Until:    viper.GetInt64(flagUntil),
+			}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Settler rights delegated to: %s\n", types.MustBech32ifyAddress(msg.Delegate))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagDelegate, "", "Delegate Address (bech32) or name")
+	cmd.Flags().Int64(flagCovID, covenant.AllCovenants, "Covenant ID, all covenants if omitted")
+	cmd.Flags().Int64(flagUntil, 0, "Last height the delegation is valid at")
+	return cmd
+}
+
+func RevokeSettlerDelegationTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke_settler_delegation",
+		Short: "Revoke a delegation of settler rights",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			settler, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			msg := covenant.MsgRevokeSettlerDelegation{settler, viper.GetInt64(flagCovID)}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Println("Settler delegation revoked")
+			return nil
+		},
+	}
+	cmd.Flags().Int64(flagCovID, covenant.AllCovenants, "Covenant ID, all covenants if omitted")
+	return cmd
+}
+
+// delegationOutput is a Delegation with bech32 addresses
+type delegationOutput struct {
+	Settler  string `json:"settler"`
+	Delegate string `json:"delegate"`
+	CovID    int64  `json:"covid"`
+	Until    int64  `json:"until"`
+}
+
+// GetDelegationCmd queries a settler's delegation on a covenant
+func GetDelegationCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delegation",
+		Short: "Query a delegation of settler rights",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper()
+
+			settlerString := viper.GetString(flagSettler)
+			if len(settlerString) == 0 {
+				return fmt.Errorf("specify settler address with --settler")
+			}
+			settlerAddr, err := parseAddress(ctx, settlerString)
+			if err != nil {
+				return err
+			}
+			covID := viper.GetInt64(flagCovID)
+
+			res, err := ctx.Query(covenant.DelegationKey(settlerAddr, covID), storeName)
+			if err != nil {
+				return err
+			}
+			if len(res) == 0 {
+				return fmt.Errorf("no delegation of %s on covenant %d", settlerString, covID)
+			}
+			var delegation covenant.Delegation
+			err = cdc.UnmarshalBinary(res, &delegation)
+			if err != nil {
+				return err
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, delegationOutput{
+				Settler:  types.MustBech32ifyAddress(delegation.Settler),
+				Delegate: types.MustBech32ifyAddress(delegation.Delegate),
+				CovID:    delegation.CovID,
+				Until:    delegation.Until,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagSettler, "", "Settler Address (bech32) or name")
+	cmd.Flags().Int64(flagCovID, covenant.AllCovenants, "Covenant ID, all covenants if omitted")
+	return cmd
+}
+
+// ChangeParamsTxCmd replaces the covenant params, signed by the params
+// authority
+func ChangeParamsTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "change_params",
+		Short: "Replace the covenant params as the params authority",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			authority, err := getFromAddress(ctx)
+			if err != nil {
+				return err
+			}
+
+			paramsFile := viper.GetString(flagParams)
+			if len(paramsFile) == 0 {
+				return fmt.Errorf("specify the JSON file with the new params with --params")
+			}
+			msg := covenant.MsgChangeParams{Authority: authority}
+			err = readJSONFile(paramsFile, &msg.Params)
+			if err != nil {
+				return err
+			}
+			msg.Params.Authority = authority
+			newAuthority := viper.GetString(flagNewAuthority)
+			if len(newAuthority) != 0 {
+				msg.Params.Authority, err = parseAddress(ctx, newAuthority)
+				if err != nil {
+					return err
+				}
+			}
+			if viper.GetBool(flagGenerateOnly) {
+				return generateTx(ctx, msg, cdc)
+			}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Println("Covenant params changed")
+			return nil
+		},
+	}
+	cmd.Flags().String(flagParams, "", "JSON file with the new params")
+	cmd.Flags().String(flagNewAuthority, "", "Address (bech32) or name of the next params authority, yourself if omitted")
+	addGenerateOnlyFlags(cmd)
+	return cmd
+}
+
+// paramsOutput is Params with a bech32 authority
+type paramsOutput struct {
+	Authority     string    `json:"authority"`
+	MaxSettlers   int64     `json:"max_settlers"`
+	MaxReceivers  int64     `json:"max_receivers"`
+	MinEscrow     sdk.Coins `json:"min_escrow"`
+	AllowedDenoms []string  `json:"allowed_denoms"`
+	FeeRate       int64     `json:"fee_rate"`
+
+	UnbondingPeriod     int64 `json:"unbonding_period"`
+	MaxPaymentsPerBlock int64 `json:"max_payments_per_block"`
+}
+
+// GetParamsCmd queries the covenant params
+func GetParamsCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "params",
+		Short: "Query the covenant params",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper()
+			res, err := ctx.Query(covenant.ParamsKey, storeName)
+			if err != nil {
+				return err
+			}
+			var params covenant.Params
+			if len(res) != 0 {
+				err = cdc.UnmarshalBinary(res, &params)
+				if err != nil {
+					return err
+				}
+			}
+
+			out := paramsOutput{
+				MaxSettlers:   params.MaxSettlers,
+				MaxReceivers:  params.MaxReceivers,
+				MinEscrow:     params.MinEscrow,
+				AllowedDenoms: params.AllowedDenoms,
+				FeeRate:       params.FeeRate,
+
+				UnbondingPeriod:     params.UnbondingPeriod,
+				MaxPaymentsPerBlock: params.MaxPaymentsPerBlock,
+			}
+			if len(params.Authority) != 0 {
+				out.Authority = types.MustBech32ifyAddress(params.Authority)
+			}
+			output, err := wire.MarshalJSONIndent(cdc, out)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
 	return cmd
 }
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Multi-party txs are built in three steps: a --generate-only tx command
+// writes the unsigned tx, every signer adds its signature with sign, possibly
+// on an air-gapped machine, and broadcast sends the complete tx.
+
+const (
+	flagGenerateOnly = "generate-only"
+	flagFromAddress  = "from-address"
+	flagOutput       = "output"
+)
+
+// addGenerateOnlyFlags adds the flags for writing an unsigned tx instead of
+// broadcasting it
+func addGenerateOnlyFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(flagGenerateOnly, false, "Write the unsigned tx as JSON instead of signing and broadcasting it")
+	cmd.Flags().String(flagFromAddress, "", "With --generate-only, the bech32 address to send from if its key is on another machine")
+	cmd.Flags().String(flagOutput, "", "With --generate-only, the file to write the tx to, stdout if omitted")
+}
+
+// getFromAddress is --from-address when generating a tx for a key that lives
+// elsewhere, and the address of the --name key otherwise.
+func getFromAddress(ctx context.CoreContext) (sdk.Address, error) {
+	fromAddress := viper.GetString(flagFromAddress)
+	if viper.GetBool(flagGenerateOnly) && len(fromAddress) != 0 {
+		return types.GetAddressBech32(fromAddress)
+	}
+	return ctx.GetFromAddress()
+}
+
+// generateTx prints msg as an unsigned StdTx. The sequence of every signer is
+// fixed now, since all signatures sign over all sequences.
+func generateTx(ctx context.CoreContext, msg sdk.Msg, cdc *wire.Codec) error {
+	signers := msg.GetSigners()
+	sigs := make([]auth.StdSignature, len(signers))
+	for i, signer := range signers {
+		seq, err := ctx.NextSequence(signer)
+		if err != nil {
+			return err
+		}
+		sigs[i] = auth.StdSignature{Sequence: seq}
+	}
+	tx := auth.NewStdTx(msg, auth.NewStdFee(ctx.Gas, sdk.Coin{}), sigs)
+
+	output, err := wire.MarshalJSONIndent(cdc, tx)
+	if err != nil {
+		return err
+	}
+	return writeOutput(output)
+}
+
+// SignTxCmd adds the signature of the --name key to a tx written by a
+// --generate-only command
+func SignTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign <file>",
+		Short: "Sign a generated transaction with a local key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper()
+			if ctx.ChainID == "" {
+				return fmt.Errorf("specify the chain the tx is for with --chain-id")
+			}
+			tx, err := readStdTx(cdc, args[0])
+			if err != nil {
+				return err
+			}
+
+			kb, err := keys.GetKeyBase()
+			if err != nil {
+				return err
+			}
+			info, err := kb.Get(ctx.FromAddressName)
+			if err != nil {
+				return err
+			}
+			index := -1
+			for i, signer := range tx.Msg.GetSigners() {
+				if bytes.Equal(signer, info.PubKey.Address()) {
+					index = i
+				}
+			}
+			if index < 0 {
+				return fmt.Errorf("key %s is not a signer of this tx", ctx.FromAddressName)
+			}
+
+			sequences := make([]int64, len(tx.Signatures))
+			for i, sig := range tx.Signatures {
+				sequences[i] = sig.Sequence
+			}
+			passphrase, err := ctx.GetPassphraseFromStdin(ctx.FromAddressName)
+			if err != nil {
+				return err
+			}
+			signBytes := auth.StdSignBytes(ctx.ChainID, sequences, tx.Fee, tx.Msg)
+			sig, pubKey, err := kb.Sign(ctx.FromAddressName, passphrase, signBytes)
+			if err != nil {
+				return err
+			}
+			tx.Signatures[index].PubKey = pubKey
+			tx.Signatures[index].Signature = sig
+
+			output, err := wire.MarshalJSONIndent(cdc, tx)
+			if err != nil {
+				return err
+			}
+			return writeOutput(output)
+		},
+	}
+	cmd.Flags().String(flagOutput, "", "File to write the signed tx to, stdout if omitted")
+	return cmd
+}
+
+// BroadcastTxCmd broadcasts a tx once every signer has signed it
+func BroadcastTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "broadcast <file>",
+		Short: "Broadcast a signed transaction",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper()
+			tx, err := readStdTx(cdc, args[0])
+			if err != nil {
+				return err
+			}
+			for i, sig := range tx.Signatures {
+				if sig.Signature == nil {
+					return fmt.Errorf("tx is missing the signature of %s", types.MustBech32ifyAddress(tx.Msg.GetSigners()[i]))
+				}
+			}
+
+			txBytes, err := cdc.MarshalBinary(tx)
+			if err != nil {
+				return err
+			}
+			res, err := ctx.BroadcastTx(txBytes)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+			return nil
+		},
+	}
+	return cmd
+}
+
+func readStdTx(cdc *wire.Codec, file string) (auth.StdTx, error) {
+	var tx auth.StdTx
+	bz, err := ioutil.ReadFile(file)
+	if err != nil {
+		return tx, err
+	}
+	err = cdc.UnmarshalJSON(bz, &tx)
+	if err != nil {
+		return tx, err
+	}
+	if len(tx.Signatures) != len(tx.Msg.GetSigners()) {
+		return tx, fmt.Errorf("tx needs one signature slot per signer")
+	}
+	return tx, nil
+}
+
+func writeOutput(output []byte) error {
+	file := viper.GetString(flagOutput)
+	if len(file) == 0 {
+		fmt.Println(string(output))
+		return nil
+	}
+	return ioutil.WriteFile(file, output, 0644)
+}
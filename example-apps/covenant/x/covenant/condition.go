@@ -0,0 +1,167 @@
+package covenant
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	cmn "github.com/tendermint/tmlibs/common"
+)
+
+// Condition types of the covenant predicate language
+const (
+	CondAnd      = "and"       // all Children hold
+	CondOr       = "or"        // at least one of Children holds
+	CondSignedBy = "signed_by" // Threshold of Addresses signed the settle tx
+	CondAfter    = "after"     // the block height is at least Height
+	CondPreimage = "preimage"  // a witness preimage hashes (sha256) to Hash
+	CondOracle   = "oracle"    // Oracle signed an attestation of Value
+)
+
+// Limits on the size of a predicate, checked when the covenant is created
+const (
+	MaxConditionDepth = 8
+	MaxConditionNodes = 64
+)
+
+// Gas charged while evaluating a predicate
+const (
+	gasPerCondition = 10
+	gasPerAddress   = 5
+	gasPerHash      = 50
+)
+
+// Condition is a node of a settlement predicate. Only the fields relevant to
+// Type are used.
+type Condition struct {
+	Type      string        `json:"type"`
+	Children  []Condition   `json:"children,omitempty"`
+	Addresses []sdk.Address `json:"addresses,omitempty"`
+	Threshold int64         `json:"threshold,omitempty"`
+	Height    int64         `json:"height,omitempty"`
+	Hash      cmn.HexBytes  `json:"hash,omitempty"`
+	Oracle    sdk.Address   `json:"oracle,omitempty"`
+	Value     string        `json:"value,omitempty"`
+}
+
+// Attestation of a value by an oracle. The oracle has to sign the settle tx.
+type Attestation struct {
+	Oracle sdk.Address `json:"oracle"`
+	Value  string      `json:"value"`
+}
+
+// Witness carries what the settler provides to satisfy a covenant's predicate.
+// Signers and the oracles of Attestations have to sign the settle tx.
+type Witness struct {
+	Signers      []sdk.Address  `json:"signers,omitempty"`
+	Preimages    []cmn.HexBytes `json:"preimages,omitempty"`
+	Attestations []Attestation  `json:"attestations,omitempty"`
+}
+
+// Validate checks the structure of a predicate
+func (c Condition) Validate() sdk.Error {
+	nodes := 0
+	return c.validate(1, &nodes)
+}
+
+func (c Condition) validate(depth int, nodes *int) sdk.Error {
+	*nodes++
+	if depth > MaxConditionDepth {
+		return ErrInvalidCondition(DefaultCodespace, fmt.Sprintf("condition nested deeper than %d", MaxConditionDepth))
+	}
+	if *nodes > MaxConditionNodes {
+		return ErrInvalidCondition(DefaultCodespace, fmt.Sprintf("condition has more than %d nodes", MaxConditionNodes))
+	}
+	switch c.Type {
+	case CondAnd, CondOr:
+		if len(c.Children) == 0 {
+			return ErrInvalidCondition(DefaultCodespace, c.Type+" needs at least one child")
+		}
+		for _, child := range c.Children {
+			err := child.validate(depth+1, nodes)
+			if err != nil {
+				return err
+			}
+		}
+	case CondSignedBy:
+		if c.Threshold <= 0 || c.Threshold > int64(len(c.Addresses)) {
+			return ErrInvalidCondition(DefaultCodespace, "signed_by threshold must be between 1 and the number of addresses")
+		}
+	case CondAfter:
+		if c.Height <= 0 {
+			return ErrInvalidCondition(DefaultCodespace, "after needs a positive height")
+		}
+	case CondPreimage:
+		if len(c.Hash) != sha256.Size {
+			return ErrInvalidCondition(DefaultCodespace, "preimage needs a sha256 hash")
+		}
+	case CondOracle:
+		if len(c.Oracle) == 0 {
+			return ErrInvalidCondition(DefaultCodespace, "oracle needs an oracle address")
+		}
+	default:
+		return ErrInvalidCondition(DefaultCodespace, fmt.Sprintf("unknown condition type %q", c.Type))
+	}
+	return nil
+}
+
+// Evaluate the predicate against a witness. signers are all addresses that
+// signed the settle tx. Gas is consumed for every node visited.
+func (c Condition) Evaluate(ctx sdk.Context, signers []sdk.Address, w Witness) bool {
+	ctx.GasMeter().ConsumeGas(gasPerCondition, "covenant condition")
+	switch c.Type {
+	case CondAnd:
+		for _, child := range c.Children {
+			if !child.Evaluate(ctx, signers, w) {
+				return false
+			}
+		}
+		return true
+	case CondOr:
+		for _, child := range c.Children {
+			if child.Evaluate(ctx, signers, w) {
+				return true
+			}
+		}
+		return false
+	case CondSignedBy:
+		ctx.GasMeter().ConsumeGas(gasPerAddress*int64(len(c.Addresses)*len(signers)), "covenant signed_by")
+		signed := int64(0)
+		for _, addr := range c.Addresses {
+			if containsAddress(signers, addr) {
+				signed++
+			}
+		}
+		return signed >= c.Threshold
+	case CondAfter:
+		return ctx.BlockHeight() >= c.Height
+	case CondPreimage:
+		for _, preimage := range w.Preimages {
+			ctx.GasMeter().ConsumeGas(gasPerHash, "covenant preimage")
+			hash := sha256.Sum256(preimage)
+			if bytes.Equal(hash[:], c.Hash) {
+				return true
+			}
+		}
+		return false
+	case CondOracle:
+		ctx.GasMeter().ConsumeGas(gasPerAddress*int64(len(w.Attestations)), "covenant oracle")
+		for _, att := range w.Attestations {
+			if bytes.Equal(att.Oracle, c.Oracle) && att.Value == c.Value {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func containsAddress(addrs []sdk.Address, addr sdk.Address) bool {
+	for _, a := range addrs {
+		if bytes.Equal(a, addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package covenant
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Reserve errors 700 ~ 799
+const (
+	DefaultCodespace sdk.CodespaceType = 7
+
+	CodeUnknownChannel          sdk.CodeType = 701
+	CodeInvalidVoucher          sdk.CodeType = 702
+	CodeChannelClosing          sdk.CodeType = 703
+	CodeChannelNotReady         sdk.CodeType = 704
+	CodeInvalidCondition        sdk.CodeType = 705
+	CodeConditionNotMet         sdk.CodeType = 706
+	CodeUnknownSubscription     sdk.CodeType = 707
+	CodeOutsideSettlementWindow sdk.CodeType = 708
+	CodeInvalidWeights          sdk.CodeType = 709
+	CodeUnknownDelegation       sdk.CodeType = 710
+	CodeInvalidParams           sdk.CodeType = 711
+	CodeInvalidCovenant         sdk.CodeType = 712
+	CodeStakeFailed             sdk.CodeType = 713
+)
+
+func ErrUnknownChannel(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownChannel, msg)
+}
+
+func ErrInvalidVoucher(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidVoucher, msg)
+}
+
+func ErrChannelClosing(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeChannelClosing, msg)
+}
+
+func ErrChannelNotReady(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeChannelNotReady, msg)
+}
+
+func ErrInvalidCondition(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCondition, msg)
+}
+
+func ErrConditionNotMet(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeConditionNotMet, msg)
+}
+
+func ErrUnknownSubscription(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownSubscription, msg)
+}
+
+func ErrOutsideSettlementWindow(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeOutsideSettlementWindow, msg)
+}
+
+func ErrInvalidWeights(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidWeights, msg)
+}
+
+func ErrUnknownDelegation(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownDelegation, msg)
+}
+
+func ErrInvalidParams(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidParams, msg)
+}
+
+func ErrInvalidCovenant(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidCovenant, msg)
+}
+
+func ErrStakeFailed(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeStakeFailed, msg)
+}
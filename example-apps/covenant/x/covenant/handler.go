@@ -12,6 +12,26 @@ func NewHandler(k Keeper) sdk.Handler {
 			return handleMsgCreate(ctx, k, msg)
 		case MsgSettleCovenant:
 			return handleMsgSettle(ctx, k, msg)
+		case MsgReclaimCovenant:
+			return handleMsgReclaim(ctx, k, msg)
+		case MsgCreateCovenants:
+			return handleMsgCreateBatch(ctx, k, msg)
+		case MsgCreateChannel:
+			return handleMsgCreateChannel(ctx, k, msg)
+		case MsgCloseChannel:
+			return handleMsgCloseChannel(ctx, k, msg)
+		case MsgFinalizeChannel:
+			return handleMsgFinalizeChannel(ctx, k, msg)
+		case MsgCreateSubscription:
+			return handleMsgCreateSubscription(ctx, k, msg)
+		case MsgCancelSubscription:
+			return handleMsgCancelSubscription(ctx, k, msg)
+		case MsgDelegateSettler:
+			return handleMsgDelegateSettler(ctx, k, msg)
+		case MsgRevokeSettlerDelegation:
+			return handleMsgRevokeSettlerDelegation(ctx, k, msg)
+		case MsgChangeParams:
+			return handleMsgChangeParams(ctx, k, msg)
 		default:
 			errMsg := "Unrecognized Escrow Msg type: " + reflect.TypeOf(msg).Name()
 			return sdk.ErrUnknownRequest(errMsg).Result()
@@ -20,7 +40,7 @@ func NewHandler(k Keeper) sdk.Handler {
 }
 
 func handleMsgCreate(ctx sdk.Context, keeper Keeper, msg MsgCreateCovenant) sdk.Result {
-	id, err := keeper.createCovenant(ctx, msg.Sender, msg.Settlers, msg.Receivers, msg.Amount)
+	id, err := keeper.createCovenant(ctx, msg.Sender, msg.covenant())
 	if err != nil {
 		return err.Result()
 	}
@@ -30,10 +50,137 @@ func handleMsgCreate(ctx sdk.Context, keeper Keeper, msg MsgCreateCovenant) sdk.
 	}
 }
 
+// The result data of a batch holds the new covenant ids, in order
+func handleMsgCreateBatch(ctx sdk.Context, keeper Keeper, msg MsgCreateCovenants) sdk.Result {
+	ids := make([]int64, len(msg.Covenants))
+	for i, cmsg := range msg.Covenants {
+		id, err := keeper.createCovenant(ctx, msg.Sender, cmsg.covenant())
+		if err != nil {
+			return err.Result()
+		}
+		ids[i] = id
+	}
+	d, _ := keeper.cdc.MarshalBinary(ids)
+	return sdk.Result{
+		Data: d,
+	}
+}
+
+// The result data of a settlement holds the approval weight still needed, 0
+// when the covenant was paid out
 func handleMsgSettle(ctx sdk.Context, keeper Keeper, msg MsgSettleCovenant) sdk.Result {
-	err := keeper.settleCovenant(ctx, msg.CovID, msg.Settler, msg.Receiver)
+	// A delegate stands in for the settler's approval only. Conditions are
+	// still evaluated against the actual signers of the tx.
+	settler := msg.Settler
+	if len(msg.OnBehalfOf) != 0 {
+		if !keeper.isActiveDelegate(ctx, msg.OnBehalfOf, msg.CovID, msg.Settler) {
+			return ErrUnknownDelegation(keeper.codespace, "no active delegation to settle on behalf of this settler").Result()
+		}
+		settler = msg.OnBehalfOf
+	}
+	remaining, err := keeper.settleCovenant(ctx, msg.CovID, settler, msg.Receiver, msg.GetSigners(), msg.Witness)
+	if err != nil {
+		return err.Result()
+	}
+	d, _ := keeper.cdc.MarshalBinary(remaining)
+	return sdk.Result{
+		Data: d,
+	}
+}
+
+func handleMsgReclaim(ctx sdk.Context, keeper Keeper, msg MsgReclaimCovenant) sdk.Result {
+	err := keeper.reclaimCovenant(ctx, msg.CovID, msg.Sender)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
+
+func handleMsgCreateChannel(ctx sdk.Context, keeper Keeper, msg MsgCreateChannel) sdk.Result {
+	id, err := keeper.createChannel(ctx, msg.Sender, msg.Receiver, msg.Amount, msg.ChallengePeriod)
+	if err != nil {
+		return err.Result()
+	}
+	d, _ := keeper.cdc.MarshalBinary(id)
+	return sdk.Result{
+		Data: d,
+	}
+}
+
+func handleMsgCloseChannel(ctx sdk.Context, keeper Keeper, msg MsgCloseChannel) sdk.Result {
+	err := keeper.closeChannel(ctx, msg.Closer, msg.Voucher, msg.PubKey, msg.Signature)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
+
+func handleMsgFinalizeChannel(ctx sdk.Context, keeper Keeper, msg MsgFinalizeChannel) sdk.Result {
+	err := keeper.finalizeChannel(ctx, msg.ChannelID)
 	if err != nil {
 		return err.Result()
 	}
 	return sdk.Result{}
 }
+
+func handleMsgCreateSubscription(ctx sdk.Context, keeper Keeper, msg MsgCreateSubscription) sdk.Result {
+	id, err := keeper.createSubscription(ctx, msg.Sender, msg.Receiver, msg.Amount, msg.Period, msg.Deposit)
+	if err != nil {
+		return err.Result()
+	}
+	d, _ := keeper.cdc.MarshalBinary(id)
+	return sdk.Result{
+		Data: d,
+	}
+}
+
+func handleMsgCancelSubscription(ctx sdk.Context, keeper Keeper, msg MsgCancelSubscription) sdk.Result {
+	err := keeper.cancelSubscription(ctx, msg.SubscriptionID, msg.Sender)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
+
+func handleMsgDelegateSettler(ctx sdk.Context, keeper Keeper, msg MsgDelegateSettler) sdk.Result {
+	delegation := Delegation{msg.Settler, msg.Delegate, msg.CovID, msg.Until}
+	err := keeper.delegateSettler(ctx, delegation)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
+
+func handleMsgRevokeSettlerDelegation(ctx sdk.Context, keeper Keeper, msg MsgRevokeSettlerDelegation) sdk.Result {
+	err := keeper.revokeSettlerDelegation(ctx, msg.Settler, msg.CovID)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
+
+func handleMsgChangeParams(ctx sdk.Context, keeper Keeper, msg MsgChangeParams) sdk.Result {
+	err := keeper.changeParams(ctx, msg.Authority, msg.Params)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
+
+// BeginBlocker moves covenants to the binary key schema in the first block
+// that runs without the migration marker in the store. The marker is part of
+// the state, so that every node migrates in the same block.
+func BeginBlocker(ctx sdk.Context, k Keeper) {
+	if k.covenantKeysMigrated(ctx) {
+		return
+	}
+	moved := k.MigrateCovenantKeys(ctx)
+	ctx.Logger().Info("Migrated covenant keys", "height", ctx.BlockHeight(), "covenants", moved)
+}
+
+// EndBlocker pays out the subscriptions that are due at this height, and the
+// delegated covenants whose unbonding period is over
+func EndBlocker(ctx sdk.Context, k Keeper) {
+	k.processSubscriptions(ctx)
+	k.processUnbondings(ctx)
+}
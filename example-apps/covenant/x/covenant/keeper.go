@@ -2,45 +2,73 @@ package covenant
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	wire "github.com/cosmos/cosmos-sdk/wire"
 	bank "github.com/cosmos/cosmos-sdk/x/bank"
+	stake "github.com/cosmos/cosmos-sdk/x/stake"
+	crypto "github.com/tendermint/go-crypto"
 	"strconv"
 	"strings"
 )
 
 type Keeper struct {
-	covStoreKey sdk.StoreKey
-	bankKeeper  bank.Keeper
-	cdc         *wire.Codec
+	covStoreKey  sdk.StoreKey
+	bankKeeper   bank.Keeper
+	stakeKeeper  stake.Keeper
+	stakeHandler sdk.Handler
+	cdc          *wire.Codec
+
+	codespace sdk.CodespaceType
 }
 
-func NewKeeper(cdc *wire.Codec, covKey sdk.StoreKey, bk bank.Keeper) Keeper {
+func NewKeeper(cdc *wire.Codec, covKey sdk.StoreKey, bk bank.Keeper, sk stake.Keeper, codespace sdk.CodespaceType) Keeper {
 	return Keeper{
-		covStoreKey: covKey,
-		bankKeeper:  bk,
-		cdc:         cdc,
+		covStoreKey:  covKey,
+		bankKeeper:   bk,
+		stakeKeeper:  sk,
+		stakeHandler: stake.NewHandler(sk),
+		cdc:          cdc,
+		codespace:    codespace,
 	}
 }
 
-func (keeper Keeper) createCovenant(ctx sdk.Context, Sender sdk.Address,
-	Settlers []sdk.Address, Receivers []sdk.Address,
-	Amount sdk.Coins) (int64, sdk.Error) {
-
-	if keeper.bankKeeper.HasCoins(ctx, Sender, Amount) {
-		keeper.bankKeeper.SubtractCoins(ctx, Sender, Amount)
-		cov := Covenant{Settlers, Receivers, Amount}
-		covID := keeper.storeCovenant(ctx, cov)
-		return covID, nil
+func (keeper Keeper) createCovenant(ctx sdk.Context, Sender sdk.Address, cov Covenant) (int64, sdk.Error) {
+	if cov.NotAfter != 0 && cov.NotAfter <= ctx.BlockHeight() {
+		m := fmt.Sprintf("Covenant settlement window closes at height %d, current height: %d", cov.NotAfter, ctx.BlockHeight())
+		return 0, ErrOutsideSettlementWindow(keeper.codespace, m)
 	}
-	return 0, sdk.ErrInsufficientFunds("no funds for covenant")
-
+	params := keeper.GetParams(ctx)
+	err := params.check(keeper.codespace, cov)
+	if err != nil {
+		return 0, err
+	}
+	err = keeper.escrow(ctx, Sender, cov.Amount.Plus(params.fee(cov.Amount)))
+	if err != nil {
+		return 0, err
+	}
+	covID := keeper.storeCovenant(ctx, cov)
+	if len(cov.Validator) != 0 {
+		err = keeper.delegateEscrow(ctx, covID, cov)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return covID, nil
 }
 
+// settleCovenant pays the covenant out, or records the settler's approval.
+// It returns the weight still needed to settle, which is 0 once paid out.
 func (keeper Keeper) settleCovenant(ctx sdk.Context, covID int64,
-	Settler sdk.Address, Receiver sdk.Address) sdk.Error {
+	Settler sdk.Address, Receiver sdk.Address,
+	Signers []sdk.Address, Witness Witness) (int64, sdk.Error) {
 	cov := keeper.getCovenant(ctx, covID)
+	height := ctx.BlockHeight()
+	if (cov.NotBefore != 0 && height < cov.NotBefore) || (cov.NotAfter != 0 && height > cov.NotAfter) {
+		m := fmt.Sprintf("Covenant can be settled from height %d to %d, current height: %d", cov.NotBefore, cov.NotAfter, height)
+		return 0, ErrOutsideSettlementWindow(keeper.codespace, m)
+	}
 	validSettler := false
 	validReceiver := false
 	for _, s := range cov.Settlers {
@@ -50,7 +78,7 @@ func (keeper Keeper) settleCovenant(ctx sdk.Context, covID int64,
 	}
 	if !validSettler {
 		m := fmt.Sprintf("Invalid Settler address, received: %s, needed: %s", Settler, cov.Settlers)
-		return sdk.ErrInvalidAddress(m)
+		return 0, sdk.ErrInvalidAddress(m)
 	}
 	for _, r := range cov.Receivers {
 		if bytes.Equal(r, Receiver) {
@@ -59,13 +87,390 @@ func (keeper Keeper) settleCovenant(ctx sdk.Context, covID int64,
 	}
 	if !validReceiver {
 		m := fmt.Sprintf("Invalid Receiver address, received: %s, needed: %s", Receiver, cov.Receivers)
-		return sdk.ErrInvalidAddress(m)
+		return 0, sdk.ErrInvalidAddress(m)
+	}
+	if cov.Threshold > 0 {
+		weight := cov.approve(Settler, Receiver)
+		if weight < cov.Threshold {
+			keeper.setCovenant(ctx, covID, cov)
+			return cov.Threshold - weight, nil
+		}
+	}
+	if cov.Condition != nil && !cov.Condition.Evaluate(ctx, Signers, Witness) {
+		return 0, ErrConditionNotMet(keeper.codespace, "witness does not satisfy the covenant condition")
+	}
+	var err sdk.Error
+	if len(cov.Validator) != 0 {
+		err = keeper.undelegateEscrow(ctx, covID, cov, Receiver)
+	} else {
+		err = keeper.payout(ctx, Receiver, cov.Amount)
+	}
+	if err != nil {
+		return 0, err
+	}
+	keeper.deleteCovenant(ctx, covID)
+	return 0, nil
+}
+
+// reclaimCovenant returns the escrow to the sender once the settlement window
+// is over. A delegated escrow is unbonded to the sender first.
+func (keeper Keeper) reclaimCovenant(ctx sdk.Context, covID int64, sender sdk.Address) sdk.Error {
+	cov := keeper.getCovenant(ctx, covID)
+	if len(cov.Sender) == 0 || !bytes.Equal(cov.Sender, sender) {
+		return sdk.ErrUnauthorized("only the covenant sender can reclaim it")
+	}
+	height := ctx.BlockHeight()
+	if cov.NotAfter == 0 || height <= cov.NotAfter {
+		m := fmt.Sprintf("Covenant can be reclaimed after height %d, current height: %d", cov.NotAfter, height)
+		return ErrOutsideSettlementWindow(keeper.codespace, m)
+	}
+	var err sdk.Error
+	if len(cov.Validator) != 0 {
+		err = keeper.undelegateEscrow(ctx, covID, cov, sender)
+	} else {
+		err = keeper.payout(ctx, sender, cov.Amount)
+	}
+	if err != nil {
+		return err
 	}
-	keeper.bankKeeper.AddCoins(ctx, Receiver, cov.Amount)
 	keeper.deleteCovenant(ctx, covID)
 	return nil
 }
 
+// escrow takes amount out of the from account and holds it in the module
+func (keeper Keeper) escrow(ctx sdk.Context, from sdk.Address, amount sdk.Coins) sdk.Error {
+	if !keeper.bankKeeper.HasCoins(ctx, from, amount) {
+		return sdk.ErrInsufficientFunds("no funds for covenant")
+	}
+	_, _, err := keeper.bankKeeper.SubtractCoins(ctx, from, amount)
+	return err
+}
+
+// payout releases escrowed coins to the given address
+func (keeper Keeper) payout(ctx sdk.Context, to sdk.Address, amount sdk.Coins) sdk.Error {
+	if amount.IsZero() {
+		return nil
+	}
+	_, _, err := keeper.bankKeeper.AddCoins(ctx, to, amount)
+	return err
+}
+
+//___________________________________________________________________________________
+
+func (keeper Keeper) createChannel(ctx sdk.Context, sender sdk.Address,
+	receiver sdk.Address, amount sdk.Coins, challengePeriod int64) (int64, sdk.Error) {
+
+	params := keeper.GetParams(ctx)
+	err := params.checkEscrow(keeper.codespace, amount)
+	if err != nil {
+		return 0, err
+	}
+	err = keeper.escrow(ctx, sender, amount.Plus(params.fee(amount)))
+	if err != nil {
+		return 0, err
+	}
+	channel := Channel{
+		Sender:          sender,
+		Receiver:        receiver,
+		Amount:          amount,
+		ChallengePeriod: challengePeriod,
+		Nonce:           -1,
+	}
+	chanID := keeper.getNewID(ctx, "nextChannelID")
+	keeper.setChannel(ctx, chanID, channel)
+	return chanID, nil
+}
+
+// closeChannel starts, or updates during the challenge window, the closing of
+// a channel. A voucher is only applied if it carries a newer nonce than the
+// one already recorded, and pays at least as much, so that the sender can't
+// replace the receiver's claim with a smaller one during the window.
+func (keeper Keeper) closeChannel(ctx sdk.Context, closer sdk.Address, voucher Voucher,
+	pubKey crypto.PubKey, sig crypto.Signature) sdk.Error {
+
+	channel, found := keeper.getChannel(ctx, voucher.ChannelID)
+	if !found {
+		return ErrUnknownChannel(keeper.codespace, fmt.Sprintf("no channel with id %d", voucher.ChannelID))
+	}
+	if !bytes.Equal(closer, channel.Sender) && !bytes.Equal(closer, channel.Receiver) {
+		return sdk.ErrUnauthorized("only the channel parties can close it")
+	}
+	if channel.Closing && ctx.BlockHeight() >= channel.ClosingHeight {
+		return ErrChannelClosing(keeper.codespace, "challenge window is over, finalize the channel")
+	}
+
+	if sig != nil {
+		if !bytes.Equal(pubKey.Address(), channel.Sender) {
+			return ErrInvalidVoucher(keeper.codespace, "voucher must be signed by the channel sender")
+		}
+		if !pubKey.VerifyBytes(voucher.GetSignBytes(ctx.ChainID()), sig) {
+			return ErrInvalidVoucher(keeper.codespace, "invalid voucher signature")
+		}
+		if !voucher.Amount.IsNotNegative() || !channel.Amount.IsGTE(voucher.Amount) {
+			return ErrInvalidVoucher(keeper.codespace, "voucher amount exceeds the channel escrow")
+		}
+		if voucher.Nonce <= channel.Nonce {
+			return ErrInvalidVoucher(keeper.codespace, fmt.Sprintf("stale voucher nonce %d, have %d", voucher.Nonce, channel.Nonce))
+		}
+		if !voucher.Amount.IsGTE(channel.Balance) {
+			return ErrInvalidVoucher(keeper.codespace, fmt.Sprintf("voucher pays %s, less than the %s already claimed", voucher.Amount, channel.Balance))
+		}
+		channel.Balance = voucher.Amount
+		channel.Nonce = voucher.Nonce
+	}
+
+	if !channel.Closing {
+		channel.Closing = true
+		channel.ClosingHeight = ctx.BlockHeight() + channel.ChallengePeriod
+	}
+	keeper.setChannel(ctx, voucher.ChannelID, channel)
+	return nil
+}
+
+// finalizeChannel pays the voucher balance to the receiver and refunds the
+// remainder to the sender once the challenge window has passed.
+func (keeper Keeper) finalizeChannel(ctx sdk.Context, chanID int64) sdk.Error {
+	channel, found := keeper.getChannel(ctx, chanID)
+	if !found {
+		return ErrUnknownChannel(keeper.codespace, fmt.Sprintf("no channel with id %d", chanID))
+	}
+	if !channel.Closing || ctx.BlockHeight() < channel.ClosingHeight {
+		return ErrChannelNotReady(keeper.codespace, "channel is still open or inside its challenge window")
+	}
+	err := keeper.payout(ctx, channel.Receiver, channel.Balance)
+	if err != nil {
+		return err
+	}
+	err = keeper.payout(ctx, channel.Sender, channel.Amount.Minus(channel.Balance))
+	if err != nil {
+		return err
+	}
+	keeper.deleteChannel(ctx, chanID)
+	return nil
+}
+
+//___________________________________________________________________________________
+
+func (keeper Keeper) delegateSettler(ctx sdk.Context, delegation Delegation) sdk.Error {
+	if delegation.Until < ctx.BlockHeight() {
+		return sdk.ErrUnknownRequest("delegation would already be expired")
+	}
+	if bytes.Equal(delegation.Settler, delegation.Delegate) {
+		return sdk.ErrInvalidAddress("cannot delegate to yourself")
+	}
+	keeper.setDelegation(ctx, delegation)
+	return nil
+}
+
+func (keeper Keeper) revokeSettlerDelegation(ctx sdk.Context, settler sdk.Address, covID int64) sdk.Error {
+	store := ctx.KVStore(keeper.covStoreKey)
+	key := DelegationKey(settler, covID)
+	if !store.Has(key) {
+		return ErrUnknownDelegation(keeper.codespace, fmt.Sprintf("no delegation of %s on covenant %d", settler, covID))
+	}
+	store.Delete(key)
+	return nil
+}
+
+// isActiveDelegate checks for an unexpired delegation from settler to
+// delegate, either for this covenant or for all of the settler's covenants.
+func (keeper Keeper) isActiveDelegate(ctx sdk.Context, settler sdk.Address, covID int64, delegate sdk.Address) bool {
+	for _, id := range []int64{covID, AllCovenants} {
+		delegation, found := keeper.getDelegation(ctx, settler, id)
+		if found && bytes.Equal(delegation.Delegate, delegate) && ctx.BlockHeight() <= delegation.Until {
+			return true
+		}
+	}
+	return false
+}
+
+func (keeper Keeper) getDelegation(ctx sdk.Context, settler sdk.Address, covID int64) (Delegation, bool) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz := store.Get(DelegationKey(settler, covID))
+	if bz == nil {
+		return Delegation{}, false
+	}
+	var delegation Delegation
+	keeper.cdc.UnmarshalBinary(bz, &delegation)
+	return delegation, true
+}
+
+func (keeper Keeper) setDelegation(ctx sdk.Context, delegation Delegation) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz, _ := keeper.cdc.MarshalBinary(delegation)
+	store.Set(DelegationKey(delegation.Settler, delegation.CovID), bz)
+}
+
+// DelegationKey is where a settler's delegation on a covenant is stored. It is
+// exported so that clients can query it.
+func DelegationKey(settler sdk.Address, covID int64) []byte {
+	return []byte(strings.Join([]string{"delegations", settler.String(), strconv.FormatInt(covID, 10)}, ":"))
+}
+
+//___________________________________________________________________________________
+
+func (keeper Keeper) createSubscription(ctx sdk.Context, sender sdk.Address, receiver sdk.Address,
+	amount sdk.Coins, period int64, deposit sdk.Coins) (int64, sdk.Error) {
+
+	params := keeper.GetParams(ctx)
+	err := params.checkEscrow(keeper.codespace, deposit)
+	if err != nil {
+		return 0, err
+	}
+	err = keeper.escrow(ctx, sender, deposit.Plus(params.fee(deposit)))
+	if err != nil {
+		return 0, err
+	}
+	sub := Subscription{
+		Sender:     sender,
+		Receiver:   receiver,
+		Amount:     amount,
+		Period:     period,
+		Escrow:     deposit,
+		NextHeight: ctx.BlockHeight() + period,
+	}
+	subID := keeper.getNewID(ctx, "nextSubscriptionID")
+	keeper.setSubscription(ctx, subID, sub)
+	keeper.schedule(ctx, sub.NextHeight, subID)
+	return subID, nil
+}
+
+// cancelSubscription stops future payments and refunds the remaining escrow
+func (keeper Keeper) cancelSubscription(ctx sdk.Context, subID int64, sender sdk.Address) sdk.Error {
+	sub, found := keeper.getSubscription(ctx, subID)
+	if !found {
+		return ErrUnknownSubscription(keeper.codespace, fmt.Sprintf("no subscription with id %d", subID))
+	}
+	if !bytes.Equal(sub.Sender, sender) {
+		return sdk.ErrUnauthorized("only the subscription sender can cancel it")
+	}
+	err := keeper.payout(ctx, sub.Sender, sub.Escrow)
+	if err != nil {
+		return err
+	}
+	keeper.unschedule(ctx, sub.NextHeight, subID)
+	keeper.deleteSubscription(ctx, subID)
+	return nil
+}
+
+// processSubscriptions makes the payments due at or before the current
+// height, oldest first and up to the max payments per block of the params.
+// The rest stay scheduled and are made in the next blocks.
+// A subscription whose escrow can't cover a payment is ended and the
+// rest of its escrow goes back to the sender. A payment that fails leaves the
+// escrow untouched and is retried a period later.
+func (keeper Keeper) processSubscriptions(ctx sdk.Context) {
+	maxPayments := keeper.GetParams(ctx).maxPaymentsPerBlock()
+	store := ctx.KVStore(keeper.covStoreKey)
+	iter := store.Iterator(prefixScheduleKey(0), prefixScheduleKey(ctx.BlockHeight()+1))
+	var keys [][]byte
+	var subIDs []int64
+	for ; iter.Valid() && len(subIDs) < maxPayments; iter.Next() {
+		keys = append(keys, iter.Key())
+		var subID int64
+		keeper.cdc.UnmarshalBinary(iter.Value(), &subID)
+		subIDs = append(subIDs, subID)
+	}
+	iter.Close()
+
+	for i, subID := range subIDs {
+		store.Delete(keys[i])
+		sub, found := keeper.getSubscription(ctx, subID)
+		if !found {
+			continue
+		}
+		if !sub.Escrow.IsGTE(sub.Amount) {
+			keeper.endSubscription(ctx, subID, sub)
+			continue
+		}
+		err := keeper.payout(ctx, sub.Receiver, sub.Amount)
+		if err != nil {
+			ctx.Logger().Error("Could not pay subscription", "subscription", subID, "log", err.ABCILog())
+		} else {
+			sub.Escrow = sub.Escrow.Minus(sub.Amount)
+			if !sub.Escrow.IsGTE(sub.Amount) {
+				keeper.endSubscription(ctx, subID, sub)
+				continue
+			}
+		}
+		sub.NextHeight += sub.Period
+		keeper.setSubscription(ctx, subID, sub)
+		keeper.schedule(ctx, sub.NextHeight, subID)
+	}
+}
+
+// endSubscription refunds what is left of an underfunded subscription's escrow.
+// If the refund fails, the subscription is kept unscheduled so that the sender
+// can still cancel it.
+func (keeper Keeper) endSubscription(ctx sdk.Context, subID int64, sub Subscription) {
+	err := keeper.payout(ctx, sub.Sender, sub.Escrow)
+	if err != nil {
+		ctx.Logger().Error("Could not refund subscription", "subscription", subID, "log", err.ABCILog())
+		keeper.setSubscription(ctx, subID, sub)
+		return
+	}
+	keeper.deleteSubscription(ctx, subID)
+}
+
+func (keeper Keeper) getSubscription(ctx sdk.Context, subID int64) (Subscription, bool) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz := store.Get(prefixArrayKey("subscriptions", subID))
+	if bz == nil {
+		return Subscription{}, false
+	}
+	var sub Subscription
+	keeper.cdc.UnmarshalBinary(bz, &sub)
+	return sub, true
+}
+
+func (keeper Keeper) setSubscription(ctx sdk.Context, subID int64, sub Subscription) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz, _ := keeper.cdc.MarshalBinary(sub)
+	store.Set(prefixArrayKey("subscriptions", subID), bz)
+}
+
+func (keeper Keeper) deleteSubscription(ctx sdk.Context, subID int64) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	store.Delete(prefixArrayKey("subscriptions", subID))
+}
+
+func (keeper Keeper) schedule(ctx sdk.Context, height int64, subID int64) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz, _ := keeper.cdc.MarshalBinary(subID)
+	store.Set(scheduleKey(height, subID), bz)
+}
+
+func (keeper Keeper) unschedule(ctx sdk.Context, height int64, subID int64) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	store.Delete(scheduleKey(height, subID))
+}
+
+// The schedule index is keyed by big-endian height, so iterating it returns
+// subscriptions in the order their payments fall due.
+func prefixScheduleKey(height int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(height))
+	return append([]byte("schedule:"), bz...)
+}
+
+func scheduleKey(height int64, subID int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(subID))
+	return append(prefixScheduleKey(height), bz...)
+}
+
+// Covenants are keyed by big-endian id under a one byte prefix, so iterating
+// them returns covenants in the order they were created.
+var covenantKeyPrefix = []byte{0x01}
+
+// CovenantKey is where a covenant is stored. It is exported so that clients
+// can query it.
+func CovenantKey(covID int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(covID))
+	return append(covenantKeyPrefix, bz...)
+}
+
 func prefixArrayKey(name string, index int64) []byte {
 	return []byte(strings.Join([]string{"arrays", name, strconv.FormatInt(index, 10)}, ":"))
 }
@@ -74,8 +479,7 @@ func prefixVariableKey(name string) []byte {
 }
 func (keeper Keeper) getCovenant(ctx sdk.Context, covID int64) Covenant {
 	store := ctx.KVStore(keeper.covStoreKey)
-	covKey := prefixArrayKey("covenants", covID)
-	bz := store.Get(covKey)
+	bz := store.Get(CovenantKey(covID))
 	var cov Covenant
 	keeper.cdc.UnmarshalBinary(bz, &cov)
 	return cov
@@ -83,26 +487,55 @@ func (keeper Keeper) getCovenant(ctx sdk.Context, covID int64) Covenant {
 
 func (keeper Keeper) deleteCovenant(ctx sdk.Context, covID int64) {
 	store := ctx.KVStore(keeper.covStoreKey)
-	store.Delete(prefixArrayKey("covenants", covID))
+	store.Delete(CovenantKey(covID))
 }
 
 func (keeper Keeper) storeCovenant(ctx sdk.Context, cov Covenant) int64 {
 	covID := keeper.getNewCovenantID(ctx)
+	keeper.setCovenant(ctx, covID, cov)
+	return covID
+}
+
+func (keeper Keeper) setCovenant(ctx sdk.Context, covID int64, cov Covenant) {
 	store := ctx.KVStore(keeper.covStoreKey)
-	covKey := prefixArrayKey("covenants", covID)
 	bz, _ := keeper.cdc.MarshalBinary(cov)
-	store.Set(covKey, bz)
-	return covID
+	store.Set(CovenantKey(covID), bz)
 }
 
 func (keeper Keeper) getNewCovenantID(ctx sdk.Context) int64 {
+	return keeper.getNewID(ctx, "nextCovenantID")
+}
+
+func (keeper Keeper) getNewID(ctx sdk.Context, counter string) int64 {
 	store := ctx.KVStore(keeper.covStoreKey)
-	bz := store.Get(prefixVariableKey("nextCovenantID"))
-	nextCovID := int64(0)
+	bz := store.Get(prefixVariableKey(counter))
+	nextID := int64(0)
 	if bz != nil {
-		keeper.cdc.UnmarshalBinary(bz, &nextCovID)
+		keeper.cdc.UnmarshalBinary(bz, &nextID)
+	}
+	bz, _ = keeper.cdc.MarshalBinary(nextID + 1)
+	store.Set(prefixVariableKey(counter), bz)
+	return nextID
+}
+
+func (keeper Keeper) getChannel(ctx sdk.Context, chanID int64) (Channel, bool) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz := store.Get(prefixArrayKey("channels", chanID))
+	if bz == nil {
+		return Channel{}, false
 	}
-	bz, _ = keeper.cdc.MarshalBinary(nextCovID + 1)
-	store.Set(prefixVariableKey("nextCovenantID"), bz)
-	return nextCovID
+	var channel Channel
+	keeper.cdc.UnmarshalBinary(bz, &channel)
+	return channel, true
+}
+
+func (keeper Keeper) setChannel(ctx sdk.Context, chanID int64, channel Channel) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz, _ := keeper.cdc.MarshalBinary(channel)
+	store.Set(prefixArrayKey("channels", chanID), bz)
+}
+
+func (keeper Keeper) deleteChannel(ctx sdk.Context, chanID int64) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	store.Delete(prefixArrayKey("channels", chanID))
 }
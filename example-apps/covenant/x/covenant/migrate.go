@@ -0,0 +1,55 @@
+package covenant
+
+import (
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Covenants used to be stored under string keys with a decimal id
+var legacyCovenantKeyPrefix = []byte("arrays:covenants:")
+
+// Set once the covenant keys are migrated
+var covenantKeysMigratedKey = prefixVariableKey("covenantKeysMigrated")
+
+// MigrateCovenantKeys moves every covenant stored under a legacy
+// "arrays:covenants:<id>" key to its CovenantKey, and returns how many were
+// moved. A key without a valid id is logged and left in place rather than
+// halting the chain. It then marks the store as migrated.
+func (keeper Keeper) MigrateCovenantKeys(ctx sdk.Context) int {
+	store := ctx.KVStore(keeper.covStoreKey)
+	iter := store.Iterator(legacyCovenantKeyPrefix, prefixEnd(legacyCovenantKeyPrefix))
+	var keys, values [][]byte
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+		values = append(values, iter.Value())
+	}
+	iter.Close()
+
+	moved := 0
+	for i, key := range keys {
+		covID, err := strconv.ParseInt(string(key[len(legacyCovenantKeyPrefix):]), 10, 64)
+		if err != nil || covID < 0 {
+			ctx.Logger().Error("Skipping malformed legacy covenant key", "key", string(key))
+			continue
+		}
+		store.Set(CovenantKey(covID), values[i])
+		store.Delete(key)
+		moved++
+	}
+	store.Set(covenantKeysMigratedKey, []byte{1})
+	return moved
+}
+
+func (keeper Keeper) covenantKeysMigrated(ctx sdk.Context) bool {
+	store := ctx.KVStore(keeper.covStoreKey)
+	return store.Has(covenantKeysMigratedKey)
+}
+
+// prefixEnd is the first key after every key that starts with prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	end[len(end)-1]++
+	return end
+}
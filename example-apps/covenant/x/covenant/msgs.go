@@ -1,8 +1,12 @@
 package covenant
 
 import (
+	"bytes"
 	"encoding/json"
+	"math"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	crypto "github.com/tendermint/go-crypto"
 )
 
 type MsgCreateCovenant struct {
@@ -10,6 +14,15 @@ type MsgCreateCovenant struct {
 	Settlers  []sdk.Address `json:"settlers"`
 	Receivers []sdk.Address `json:"receivers"`
 	Amount    sdk.Coins     `json:"amount"`
+	Condition *Condition    `json:"condition,omitempty"`
+	NotBefore int64         `json:"not_before,omitempty"`
+	NotAfter  int64         `json:"not_after,omitempty"`
+	Weights   []int64       `json:"weights,omitempty"`
+	Threshold int64         `json:"threshold,omitempty"`
+
+	// Set to keep the escrow delegated to a validator
+	Validator      sdk.Address `json:"validator,omitempty"`
+	RewardReceiver sdk.Address `json:"reward_receiver,omitempty"`
 }
 
 func (mcc MsgCreateCovenant) Type() string {
@@ -22,6 +35,48 @@ func (mcc MsgCreateCovenant) GetSignBytes() []byte {
 }
 
 func (mcc MsgCreateCovenant) ValidateBasic() sdk.Error {
+	if mcc.NotBefore < 0 || mcc.NotAfter < 0 {
+		return ErrOutsideSettlementWindow(DefaultCodespace, "settlement heights cannot be negative")
+	}
+	if mcc.NotAfter != 0 && mcc.NotBefore > mcc.NotAfter {
+		return ErrOutsideSettlementWindow(DefaultCodespace, "settlement window closes before it opens")
+	}
+	for i, s := range mcc.Settlers {
+		if containsAddress(mcc.Settlers[:i], s) {
+			return sdk.ErrInvalidAddress("settlers must be distinct")
+		}
+	}
+	if len(mcc.Weights) != 0 || mcc.Threshold != 0 {
+		if len(mcc.Weights) != len(mcc.Settlers) {
+			return ErrInvalidWeights(DefaultCodespace, "need one weight per settler")
+		}
+		total := int64(0)
+		for _, w := range mcc.Weights {
+			if w <= 0 {
+				return ErrInvalidWeights(DefaultCodespace, "settler weights must be positive")
+			}
+			if w > math.MaxInt64-total {
+				return ErrInvalidWeights(DefaultCodespace, "settler weights overflow")
+			}
+			total += w
+		}
+		if mcc.Threshold <= 0 || mcc.Threshold > total {
+			return ErrInvalidWeights(DefaultCodespace, "threshold must be positive and reachable by the settler weights")
+		}
+	}
+	if len(mcc.Validator) != 0 {
+		if len(mcc.Amount) != 1 {
+			return ErrInvalidCovenant(DefaultCodespace, "a delegated covenant escrows a single coin")
+		}
+		if len(mcc.RewardReceiver) == 0 {
+			return sdk.ErrInvalidAddress("a delegated covenant needs a reward receiver")
+		}
+	} else if len(mcc.RewardReceiver) != 0 {
+		return ErrInvalidCovenant(DefaultCodespace, "a reward receiver needs a validator to delegate to")
+	}
+	if mcc.Condition != nil {
+		return mcc.Condition.Validate()
+	}
 	return nil
 }
 
@@ -29,10 +84,69 @@ func (mcc MsgCreateCovenant) GetSigners() []sdk.Address {
 	return []sdk.Address{mcc.Sender}
 }
 
+// covenant to store for this msg
+func (mcc MsgCreateCovenant) covenant() Covenant {
+	return Covenant{
+		Settlers:  mcc.Settlers,
+		Receivers: mcc.Receivers,
+		Amount:    mcc.Amount,
+		Condition: mcc.Condition,
+		NotBefore: mcc.NotBefore,
+		NotAfter:  mcc.NotAfter,
+		Weights:   mcc.Weights,
+		Threshold: mcc.Threshold,
+
+		Validator:      mcc.Validator,
+		RewardReceiver: mcc.RewardReceiver,
+
+		Sender: mcc.Sender,
+	}
+}
+
+// MsgCreateCovenants creates many covenants in one tx, all funded by Sender.
+// StdTx carries a single Msg, so batches are wrapped in their own Msg.
+type MsgCreateCovenants struct {
+	Sender    sdk.Address         `json:"sender"`
+	Covenants []MsgCreateCovenant `json:"covenants"`
+}
+
+func (mcc MsgCreateCovenants) Type() string {
+	return "covenant"
+}
+
+func (mcc MsgCreateCovenants) GetSignBytes() []byte {
+	b, _ := json.Marshal(mcc)
+	return b
+}
+
+func (mcc MsgCreateCovenants) ValidateBasic() sdk.Error {
+	if len(mcc.Covenants) == 0 {
+		return sdk.ErrUnknownRequest("batch contains no covenants")
+	}
+	for _, cov := range mcc.Covenants {
+		if !bytes.Equal(cov.Sender, mcc.Sender) {
+			return sdk.ErrInvalidAddress("all covenants of a batch must have the batch sender")
+		}
+		err := cov.ValidateBasic()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mcc MsgCreateCovenants) GetSigners() []sdk.Address {
+	return []sdk.Address{mcc.Sender}
+}
+
 type MsgSettleCovenant struct {
 	CovID    int64       `json:"covid"`
 	Settler  sdk.Address `json:"settler"`
 	Receiver sdk.Address `json:"receiver"`
+	Witness  Witness     `json:"witness"`
+
+	// Set when Settler acts as the delegate of another settler
+	OnBehalfOf sdk.Address `json:"on_behalf_of,omitempty"`
 }
 
 func (msc MsgSettleCovenant) Type() string {
@@ -45,9 +159,293 @@ func (msc MsgSettleCovenant) GetSignBytes() []byte {
 }
 
 func (msc MsgSettleCovenant) ValidateBasic() sdk.Error {
+	signers := msc.GetSigners()
+	for i, signer := range signers {
+		if containsAddress(signers[:i], signer) {
+			return sdk.ErrUnknownRequest("witness signers and oracles must be distinct from each other and the settler")
+		}
+	}
 	return nil
 }
 
+// The settler, the witness signers and every attesting oracle sign the tx
 func (msc MsgSettleCovenant) GetSigners() []sdk.Address {
-	return []sdk.Address{msc.Settler}
+	signers := []sdk.Address{msc.Settler}
+	signers = append(signers, msc.Witness.Signers...)
+	for _, att := range msc.Witness.Attestations {
+		signers = append(signers, att.Oracle)
+	}
+	return signers
+}
+
+// MsgReclaimCovenant returns the escrow of an expired covenant to its sender
+type MsgReclaimCovenant struct {
+	Sender sdk.Address `json:"sender"`
+	CovID  int64       `json:"covid"`
+}
+
+func (mrc MsgReclaimCovenant) Type() string {
+	return "covenant"
+}
+
+func (mrc MsgReclaimCovenant) GetSignBytes() []byte {
+	b, _ := json.Marshal(mrc)
+	return b
+}
+
+func (mrc MsgReclaimCovenant) ValidateBasic() sdk.Error {
+	if len(mrc.Sender) == 0 {
+		return sdk.ErrInvalidAddress("reclaim needs the covenant sender")
+	}
+	return nil
+}
+
+func (mrc MsgReclaimCovenant) GetSigners() []sdk.Address {
+	return []sdk.Address{mrc.Sender}
+}
+
+type MsgCreateChannel struct {
+	Sender          sdk.Address `json:"sender"`
+	Receiver        sdk.Address `json:"receiver"`
+	Amount          sdk.Coins   `json:"amount"`
+	ChallengePeriod int64       `json:"challenge_period"`
+}
+
+func (mcc MsgCreateChannel) Type() string {
+	return "covenant"
+}
+
+func (mcc MsgCreateChannel) GetSignBytes() []byte {
+	b, _ := json.Marshal(mcc)
+	return b
+}
+
+func (mcc MsgCreateChannel) ValidateBasic() sdk.Error {
+	if len(mcc.Sender) == 0 || len(mcc.Receiver) == 0 {
+		return sdk.ErrInvalidAddress("channel needs a sender and a receiver")
+	}
+	if !mcc.Amount.IsValid() || !mcc.Amount.IsPositive() {
+		return sdk.ErrInvalidCoins(mcc.Amount.String())
+	}
+	if mcc.ChallengePeriod <= 0 {
+		return sdk.ErrUnknownRequest("challenge period must be positive")
+	}
+	return nil
+}
+
+func (mcc MsgCreateChannel) GetSigners() []sdk.Address {
+	return []sdk.Address{mcc.Sender}
+}
+
+// MsgCloseChannel starts the challenge window of a channel. The receiver
+// submits the latest voucher with the sender's PubKey and Signature, the
+// sender may close with an empty Signature to reclaim an unused channel.
+// Inside the window the receiver can still replace the voucher with a newer one.
+type MsgCloseChannel struct {
+	Closer    sdk.Address      `json:"closer"`
+	Voucher   Voucher          `json:"voucher"`
+	PubKey    crypto.PubKey    `json:"pub_key"`
+	Signature crypto.Signature `json:"signature"`
+}
+
+func (mcc MsgCloseChannel) Type() string {
+	return "covenant"
+}
+
+func (mcc MsgCloseChannel) GetSignBytes() []byte {
+	b, _ := json.Marshal(mcc)
+	return b
+}
+
+func (mcc MsgCloseChannel) ValidateBasic() sdk.Error {
+	if len(mcc.Closer) == 0 {
+		return sdk.ErrInvalidAddress("missing closer address")
+	}
+	if mcc.Signature != nil && mcc.PubKey == nil {
+		return ErrInvalidVoucher(DefaultCodespace, "voucher signature needs the sender's public key")
+	}
+	return nil
+}
+
+func (mcc MsgCloseChannel) GetSigners() []sdk.Address {
+	return []sdk.Address{mcc.Closer}
+}
+
+// MsgFinalizeChannel pays out a channel whose challenge window has passed.
+type MsgFinalizeChannel struct {
+	Signer    sdk.Address `json:"signer"`
+	ChannelID int64       `json:"channel_id"`
+}
+
+func (mfc MsgFinalizeChannel) Type() string {
+	return "covenant"
+}
+
+func (mfc MsgFinalizeChannel) GetSignBytes() []byte {
+	b, _ := json.Marshal(mfc)
+	return b
+}
+
+func (mfc MsgFinalizeChannel) ValidateBasic() sdk.Error {
+	if len(mfc.Signer) == 0 {
+		return sdk.ErrInvalidAddress("missing signer address")
+	}
+	return nil
+}
+
+func (mfc MsgFinalizeChannel) GetSigners() []sdk.Address {
+	return []sdk.Address{mfc.Signer}
+}
+
+type MsgCreateSubscription struct {
+	Sender   sdk.Address `json:"sender"`
+	Receiver sdk.Address `json:"receiver"`
+	Amount   sdk.Coins   `json:"amount"`
+	Period   int64       `json:"period"`
+	Deposit  sdk.Coins   `json:"deposit"`
+}
+
+func (mcs MsgCreateSubscription) Type() string {
+	return "covenant"
+}
+
+func (mcs MsgCreateSubscription) GetSignBytes() []byte {
+	b, _ := json.Marshal(mcs)
+	return b
+}
+
+func (mcs MsgCreateSubscription) ValidateBasic() sdk.Error {
+	if len(mcs.Sender) == 0 || len(mcs.Receiver) == 0 {
+		return sdk.ErrInvalidAddress("subscription needs a sender and a receiver")
+	}
+	if !mcs.Amount.IsValid() || !mcs.Amount.IsPositive() {
+		return sdk.ErrInvalidCoins(mcs.Amount.String())
+	}
+	if !mcs.Deposit.IsValid() || !mcs.Deposit.IsGTE(mcs.Amount) {
+		return sdk.ErrInvalidCoins("deposit must cover at least one payment")
+	}
+	if mcs.Period <= 0 {
+		return sdk.ErrUnknownRequest("subscription period must be positive")
+	}
+	return nil
+}
+
+func (mcs MsgCreateSubscription) GetSigners() []sdk.Address {
+	return []sdk.Address{mcs.Sender}
+}
+
+type MsgCancelSubscription struct {
+	Sender         sdk.Address `json:"sender"`
+	SubscriptionID int64       `json:"subscription_id"`
+}
+
+func (mcs MsgCancelSubscription) Type() string {
+	return "covenant"
+}
+
+func (mcs MsgCancelSubscription) GetSignBytes() []byte {
+	b, _ := json.Marshal(mcs)
+	return b
+}
+
+func (mcs MsgCancelSubscription) ValidateBasic() sdk.Error {
+	if len(mcs.Sender) == 0 {
+		return sdk.ErrInvalidAddress("missing sender address")
+	}
+	return nil
+}
+
+func (mcs MsgCancelSubscription) GetSigners() []sdk.Address {
+	return []sdk.Address{mcs.Sender}
+}
+
+// MsgDelegateSettler hands the settler's approval power on CovID, or on all
+// covenants with AllCovenants, to Delegate until the Until height.
+type MsgDelegateSettler struct {
+	Settler  sdk.Address `json:"settler"`
+	Delegate sdk.Address `json:"delegate"`
+	CovID    int64       `json:"covid"`
+	Until    int64       `json:"until"`
+}
+
+func (mds MsgDelegateSettler) Type() string {
+	return "covenant"
+}
+
+func (mds MsgDelegateSettler) GetSignBytes() []byte {
+	b, _ := json.Marshal(mds)
+	return b
+}
+
+func (mds MsgDelegateSettler) ValidateBasic() sdk.Error {
+	if len(mds.Settler) == 0 || len(mds.Delegate) == 0 {
+		return sdk.ErrInvalidAddress("delegation needs a settler and a delegate")
+	}
+	if mds.CovID < AllCovenants {
+		return sdk.ErrUnknownRequest("invalid covenant id")
+	}
+	if mds.Until <= 0 {
+		return sdk.ErrUnknownRequest("delegation needs a positive end height")
+	}
+	return nil
+}
+
+func (mds MsgDelegateSettler) GetSigners() []sdk.Address {
+	return []sdk.Address{mds.Settler}
+}
+
+type MsgRevokeSettlerDelegation struct {
+	Settler sdk.Address `json:"settler"`
+	CovID   int64       `json:"covid"`
+}
+
+func (mrd MsgRevokeSettlerDelegation) Type() string {
+	return "covenant"
+}
+
+func (mrd MsgRevokeSettlerDelegation) GetSignBytes() []byte {
+	b, _ := json.Marshal(mrd)
+	return b
+}
+
+func (mrd MsgRevokeSettlerDelegation) ValidateBasic() sdk.Error {
+	if len(mrd.Settler) == 0 {
+		return sdk.ErrInvalidAddress("missing settler address")
+	}
+	return nil
+}
+
+func (mrd MsgRevokeSettlerDelegation) GetSigners() []sdk.Address {
+	return []sdk.Address{mrd.Settler}
+}
+
+// MsgChangeParams replaces the covenant params. It has to be signed by the
+// current params authority, since this SDK release has no governance module
+// to pass proposals. Without an authority, any signer can send the first one.
+type MsgChangeParams struct {
+	Authority sdk.Address `json:"authority"`
+	Params    Params      `json:"params"`
+}
+
+func (mcp MsgChangeParams) Type() string {
+	return "covenant"
+}
+
+func (mcp MsgChangeParams) GetSignBytes() []byte {
+	b, _ := json.Marshal(mcp)
+	return b
+}
+
+func (mcp MsgChangeParams) ValidateBasic() sdk.Error {
+	if len(mcp.Authority) == 0 {
+		return sdk.ErrInvalidAddress("missing authority address")
+	}
+	if len(mcp.Params.Authority) == 0 {
+		return ErrInvalidParams(DefaultCodespace, "new params need an authority, or they could never be changed again")
+	}
+	return mcp.Params.Validate()
+}
+
+func (mcp MsgChangeParams) GetSigners() []sdk.Address {
+	return []sdk.Address{mcp.Authority}
 }
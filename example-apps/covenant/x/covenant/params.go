@@ -0,0 +1,166 @@
+package covenant
+
+import (
+	"bytes"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Params limit the covenants, channels and subscriptions that can be created.
+// They are set in genesis and can be changed by the Authority address with
+// MsgChangeParams. Zero values don't limit anything.
+// This SDK release has no governance proposals, so a chain whose genesis sets
+// no Authority has its params frozen until the authority is claimed: the first
+// MsgChangeParams is accepted from any signer, once, and sets it.
+// The fee is charged on top of every escrow and burned: it leaves the sender's
+// account and isn't credited to anyone.
+type Params struct {
+	Authority     sdk.Address `json:"authority"`
+	MaxSettlers   int64       `json:"max_settlers"`
+	MaxReceivers  int64       `json:"max_receivers"`
+	MinEscrow     sdk.Coins   `json:"min_escrow"`
+	AllowedDenoms []string    `json:"allowed_denoms"`
+	FeeRate       int64       `json:"fee_rate"` // in basis points of the escrowed amount, burned
+
+	// Blocks between settling a delegated covenant and paying it out. Stake
+	// unbonds at once in this SDK release, so this delay is the module's own.
+	UnbondingPeriod int64 `json:"unbonding_period"`
+
+	// Subscription payments made per block at most, DefaultMaxPaymentsPerBlock
+	// if 0. Payments beyond it are made in the next blocks.
+	MaxPaymentsPerBlock int64 `json:"max_payments_per_block"`
+}
+
+// MaxFeeRate is a fee of the whole escrowed amount
+const MaxFeeRate = 10000
+
+// DefaultMaxPaymentsPerBlock caps the subscription payments of a block when
+// the params don't
+const DefaultMaxPaymentsPerBlock = 100
+
+// Validate checks that the params are consistent
+func (p Params) Validate() sdk.Error {
+	if p.MaxSettlers < 0 || p.MaxReceivers < 0 {
+		return ErrInvalidParams(DefaultCodespace, "max settlers and receivers cannot be negative")
+	}
+	if len(p.MinEscrow) != 0 && !p.MinEscrow.IsValid() {
+		return ErrInvalidParams(DefaultCodespace, "invalid minimum escrow")
+	}
+	if p.UnbondingPeriod < 0 {
+		return ErrInvalidParams(DefaultCodespace, "unbonding period cannot be negative")
+	}
+	if p.MaxPaymentsPerBlock < 0 {
+		return ErrInvalidParams(DefaultCodespace, "max payments per block cannot be negative")
+	}
+	if p.FeeRate < 0 || p.FeeRate > MaxFeeRate {
+		return ErrInvalidParams(DefaultCodespace, fmt.Sprintf("fee rate must be between 0 and %d basis points", MaxFeeRate))
+	}
+	return nil
+}
+
+// check validates a covenant against the params
+func (p Params) check(codespace sdk.CodespaceType, cov Covenant) sdk.Error {
+	if p.MaxSettlers != 0 && int64(len(cov.Settlers)) > p.MaxSettlers {
+		return ErrInvalidCovenant(codespace, fmt.Sprintf("covenant has more than %d settlers", p.MaxSettlers))
+	}
+	if p.MaxReceivers != 0 && int64(len(cov.Receivers)) > p.MaxReceivers {
+		return ErrInvalidCovenant(codespace, fmt.Sprintf("covenant has more than %d receivers", p.MaxReceivers))
+	}
+	return p.checkEscrow(codespace, cov.Amount)
+}
+
+// checkEscrow validates an amount to escrow against the params
+func (p Params) checkEscrow(codespace sdk.CodespaceType, amount sdk.Coins) sdk.Error {
+	if len(p.MinEscrow) != 0 && !amount.IsGTE(p.MinEscrow) {
+		return ErrInvalidCovenant(codespace, fmt.Sprintf("escrow is less than %s", p.MinEscrow))
+	}
+	if len(p.AllowedDenoms) != 0 {
+		for _, coin := range amount {
+			if !containsDenom(p.AllowedDenoms, coin.Denom) {
+				return ErrInvalidCovenant(codespace, fmt.Sprintf("denomination %s is not allowed in escrows", coin.Denom))
+			}
+		}
+	}
+	return nil
+}
+
+// maxPaymentsPerBlock is the cap on subscription payments in a block
+func (p Params) maxPaymentsPerBlock() int {
+	if p.MaxPaymentsPerBlock == 0 {
+		return DefaultMaxPaymentsPerBlock
+	}
+	return int(p.MaxPaymentsPerBlock)
+}
+
+// fee charged on top of escrowing amount. It is burned.
+func (p Params) fee(amount sdk.Coins) sdk.Coins {
+	var fee sdk.Coins
+	for _, coin := range amount {
+		feeAmount := coin.Amount * p.FeeRate / MaxFeeRate
+		if feeAmount > 0 {
+			fee = append(fee, sdk.Coin{Denom: coin.Denom, Amount: feeAmount})
+		}
+	}
+	return fee
+}
+
+func containsDenom(denoms []string, denom string) bool {
+	for _, d := range denoms {
+		if d == denom {
+			return true
+		}
+	}
+	return false
+}
+
+// Genesis is the covenant module's part of the genesis file
+type Genesis struct {
+	Params Params `json:"params"`
+}
+
+// InitGenesis stores the params from the genesis file
+func (keeper Keeper) InitGenesis(ctx sdk.Context, data Genesis) error {
+	err := data.Params.Validate()
+	if err != nil {
+		return err
+	}
+	keeper.setParams(ctx, data.Params)
+	return nil
+}
+
+// WriteGenesis returns the module state to export
+func (keeper Keeper) WriteGenesis(ctx sdk.Context) Genesis {
+	return Genesis{Params: keeper.GetParams(ctx)}
+}
+
+func (keeper Keeper) changeParams(ctx sdk.Context, authority sdk.Address, params Params) sdk.Error {
+	current := keeper.GetParams(ctx)
+	// Without an authority, the first change claims it
+	if len(current.Authority) != 0 && !bytes.Equal(current.Authority, authority) {
+		return sdk.ErrUnauthorized("only the params authority can change covenant params")
+	}
+	keeper.setParams(ctx, params)
+	return nil
+}
+
+// GetParams returns the current params, all zero if none were set
+func (keeper Keeper) GetParams(ctx sdk.Context) Params {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz := store.Get(ParamsKey)
+	var params Params
+	if bz != nil {
+		keeper.cdc.UnmarshalBinary(bz, &params)
+	}
+	return params
+}
+
+func (keeper Keeper) setParams(ctx sdk.Context, params Params) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz, _ := keeper.cdc.MarshalBinary(params)
+	store.Set(ParamsKey, bz)
+}
+
+// ParamsKey is where the params are stored. It is exported so that clients
+// can query it.
+var ParamsKey = prefixVariableKey("params")
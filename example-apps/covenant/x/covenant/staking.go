@@ -0,0 +1,151 @@
+package covenant
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	stake "github.com/cosmos/cosmos-sdk/x/stake"
+)
+
+// The stake module of this SDK release neither distributes rewards nor delays
+// unbonding: coins come back to the delegator as soon as it unbonds. So the
+// "rewards" of a delegated covenant are whatever coins land on its escrow
+// address while it is delegated, and UnbondingPeriod is a delay of the
+// covenant module only.
+
+// Unbonding is the payout of a settled delegated covenant, waiting for
+// MaturityHeight. Everything the escrow address holds beyond Principal is
+// reward and goes to RewardReceiver.
+type Unbonding struct {
+	CovID          int64
+	Receiver       sdk.Address
+	RewardReceiver sdk.Address
+	Principal      sdk.Coins
+	MaturityHeight int64
+}
+
+// EscrowAddress is the delegator of a delegated covenant's escrow. Nobody
+// holds a key for it.
+func EscrowAddress(covID int64) sdk.Address {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(covID))
+	hash := sha256.Sum256(append([]byte("covenant/escrow/"), bz...))
+	return sdk.Address(hash[:20])
+}
+
+// delegateEscrow moves the escrowed amount to the covenant's escrow address
+// and delegates it to the covenant's validator
+func (keeper Keeper) delegateEscrow(ctx sdk.Context, covID int64, cov Covenant) sdk.Error {
+	bondDenom := keeper.stakeKeeper.GetParams(ctx).BondDenom
+	if cov.Amount[0].Denom != bondDenom {
+		return ErrInvalidCovenant(keeper.codespace, fmt.Sprintf("a delegated covenant escrows %s", bondDenom))
+	}
+	escrowAddr := EscrowAddress(covID)
+	err := keeper.payout(ctx, escrowAddr, cov.Amount)
+	if err != nil {
+		return err
+	}
+	res := keeper.stakeHandler(ctx, stake.NewMsgDelegate(escrowAddr, cov.Validator, cov.Amount[0]))
+	if !res.IsOK() {
+		return ErrStakeFailed(keeper.codespace, res.Log)
+	}
+	return nil
+}
+
+// undelegateEscrow unbonds a settled covenant's escrow and schedules its
+// payout to receiver after the unbonding period
+func (keeper Keeper) undelegateEscrow(ctx sdk.Context, covID int64, cov Covenant, receiver sdk.Address) sdk.Error {
+	escrowAddr := EscrowAddress(covID)
+	_, found := keeper.stakeKeeper.GetDelegation(ctx, escrowAddr, cov.Validator)
+	if found {
+		res := keeper.stakeHandler(ctx, stake.NewMsgUnbond(escrowAddr, cov.Validator, "MAX"))
+		if !res.IsOK() {
+			return ErrStakeFailed(keeper.codespace, res.Log)
+		}
+	}
+	unbonding := Unbonding{
+		CovID:          covID,
+		Receiver:       receiver,
+		RewardReceiver: cov.RewardReceiver,
+		Principal:      cov.Amount,
+		MaturityHeight: ctx.BlockHeight() + keeper.GetParams(ctx).UnbondingPeriod,
+	}
+	store := ctx.KVStore(keeper.covStoreKey)
+	bz, _ := keeper.cdc.MarshalBinary(unbonding)
+	store.Set(unbondingKey(unbonding.MaturityHeight, covID), bz)
+	return nil
+}
+
+// processUnbondings pays out the delegated covenants whose unbonding period
+// is over. The receiver gets up to the principal, which is less if the
+// validator was slashed, and the reward receiver gets the rest.
+func (keeper Keeper) processUnbondings(ctx sdk.Context) {
+	store := ctx.KVStore(keeper.covStoreKey)
+	iter := store.Iterator(prefixUnbondingKey(0), prefixUnbondingKey(ctx.BlockHeight()+1))
+	var keys [][]byte
+	var unbondings []Unbonding
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+		var unbonding Unbonding
+		keeper.cdc.UnmarshalBinary(iter.Value(), &unbonding)
+		unbondings = append(unbondings, unbonding)
+	}
+	iter.Close()
+
+	for i, unbonding := range unbondings {
+		store.Delete(keys[i])
+		left, err := keeper.payUnbonding(ctx, unbonding)
+		if err != nil {
+			ctx.Logger().Error("Could not pay out delegated covenant", "covenant", unbonding.CovID, "log", err.ABCILog())
+			left.MaturityHeight = ctx.BlockHeight() + 1
+			bz, _ := keeper.cdc.MarshalBinary(left)
+			store.Set(unbondingKey(left.MaturityHeight, left.CovID), bz)
+		}
+	}
+}
+
+// payUnbonding empties the escrow address of a matured unbonding. On error,
+// the coins not paid yet are back on the escrow address, and the returned
+// unbonding pays them on the next try.
+func (keeper Keeper) payUnbonding(ctx sdk.Context, unbonding Unbonding) (Unbonding, sdk.Error) {
+	escrowAddr := EscrowAddress(unbonding.CovID)
+	coins := keeper.bankKeeper.GetCoins(ctx, escrowAddr)
+	principal := unbonding.Principal
+	if !coins.IsGTE(principal) {
+		principal = coins
+	}
+	_, _, err := keeper.bankKeeper.SubtractCoins(ctx, escrowAddr, coins)
+	if err != nil {
+		return unbonding, err
+	}
+
+	err = keeper.payout(ctx, unbonding.Receiver, principal)
+	if err != nil {
+		keeper.payout(ctx, escrowAddr, coins)
+		return unbonding, err
+	}
+	unbonding.Principal = nil
+	rewards := coins.Minus(principal)
+	err = keeper.payout(ctx, unbonding.RewardReceiver, rewards)
+	if err != nil {
+		keeper.payout(ctx, escrowAddr, rewards)
+		return unbonding, err
+	}
+	return unbonding, nil
+}
+
+// The unbonding index is keyed by big-endian maturity height, like the
+// subscription schedule
+func prefixUnbondingKey(height int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(height))
+	return append([]byte("unbondings:"), bz...)
+}
+
+func unbondingKey(height int64, covID int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(covID))
+	return append(prefixUnbondingKey(height), bz...)
+}
@@ -1,11 +1,118 @@
 package covenant
 
 import (
+	"bytes"
+	"encoding/json"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// Covenant can only be settled between the NotBefore and NotAfter heights,
+// inclusive. A zero bound is not enforced.
+// With a Threshold, settlement needs the summed Weights of the settlers
+// approving the same receiver to reach it. Weights follow the Settlers order.
+// With a Validator, the escrow stays delegated to it until settlement, and
+// the rewards go to RewardReceiver.
+// Once NotAfter has passed, the Sender can reclaim the escrow.
 type Covenant struct {
 	Settlers  []sdk.Address
 	Receivers []sdk.Address
 	Amount    sdk.Coins
+	Condition *Condition
+	NotBefore int64
+	NotAfter  int64
+	Weights   []int64
+	Threshold int64
+	Approvals []Approval
+
+	Validator      sdk.Address
+	RewardReceiver sdk.Address
+
+	Sender sdk.Address
+}
+
+// Approval of a settler for paying the covenant to Receiver
+type Approval struct {
+	Settler  sdk.Address
+	Receiver sdk.Address
+}
+
+// approve records the settler's approval, replacing an earlier one, and
+// returns the weight approving the same receiver.
+func (cov *Covenant) approve(settler sdk.Address, receiver sdk.Address) int64 {
+	approvals := []Approval{}
+	for _, a := range cov.Approvals {
+		if !bytes.Equal(a.Settler, settler) {
+			approvals = append(approvals, a)
+		}
+	}
+	cov.Approvals = append(approvals, Approval{settler, receiver})
+
+	weight := int64(0)
+	for _, a := range cov.Approvals {
+		if !bytes.Equal(a.Receiver, receiver) {
+			continue
+		}
+		for i, s := range cov.Settlers {
+			if bytes.Equal(s, a.Settler) {
+				weight += cov.Weights[i]
+			}
+		}
+	}
+	return weight
+}
+
+// Channel is a unidirectional payment channel. Sender escrows Amount for
+// Receiver, and pays off-chain by signing vouchers with a growing cumulative
+// Balance. Once Closing, the channel can be paid out from ClosingHeight on.
+type Channel struct {
+	Sender          sdk.Address
+	Receiver        sdk.Address
+	Amount          sdk.Coins
+	ChallengePeriod int64
+	Balance         sdk.Coins
+	Nonce           int64
+	Closing         bool
+	ClosingHeight   int64
+}
+
+// AllCovenants as the covenant of a Delegation covers every covenant of the settler
+const AllCovenants int64 = -1
+
+// Delegation lets Delegate settle in place of Settler until the Until height
+type Delegation struct {
+	Settler  sdk.Address `json:"settler"`
+	Delegate sdk.Address `json:"delegate"`
+	CovID    int64       `json:"covid"`
+	Until    int64       `json:"until"`
+}
+
+// Subscription pays Amount to Receiver every Period blocks out of the
+// prefunded Escrow, until the escrow runs out or the sender cancels.
+type Subscription struct {
+	Sender     sdk.Address
+	Receiver   sdk.Address
+	Amount     sdk.Coins
+	Period     int64
+	Escrow     sdk.Coins
+	NextHeight int64
+}
+
+// Voucher is signed off-chain by the channel sender. Amount is cumulative,
+// so only the voucher with the highest Nonce matters, and it can never pay
+// less than an earlier one.
+type Voucher struct {
+	ChannelID int64     `json:"channel_id"`
+	Amount    sdk.Coins `json:"amount"`
+	Nonce     int64     `json:"nonce"`
+}
+
+// Bytes the channel sender signs to issue a voucher. They include the chain
+// ID, so that a voucher can't be replayed on another chain.
+func (v Voucher) GetSignBytes(chainID string) []byte {
+	b, _ := json.Marshal(struct {
+		ChainID string  `json:"chain_id"`
+		Voucher Voucher `json:"voucher"`
+	}{chainID, v})
+	return b
 }
@@ -8,4 +8,14 @@ import (
 func RegisterWire(cdc *wire.Codec) {
 	cdc.RegisterConcrete(MsgCreateCovenant{}, "covenant/create", nil)
 	cdc.RegisterConcrete(MsgSettleCovenant{}, "covenant/settle", nil)
+	cdc.RegisterConcrete(MsgReclaimCovenant{}, "covenant/reclaim", nil)
+	cdc.RegisterConcrete(MsgCreateCovenants{}, "covenant/createBatch", nil)
+	cdc.RegisterConcrete(MsgCreateChannel{}, "covenant/createChannel", nil)
+	cdc.RegisterConcrete(MsgCloseChannel{}, "covenant/closeChannel", nil)
+	cdc.RegisterConcrete(MsgFinalizeChannel{}, "covenant/finalizeChannel", nil)
+	cdc.RegisterConcrete(MsgCreateSubscription{}, "covenant/createSubscription", nil)
+	cdc.RegisterConcrete(MsgCancelSubscription{}, "covenant/cancelSubscription", nil)
+	cdc.RegisterConcrete(MsgDelegateSettler{}, "covenant/delegateSettler", nil)
+	cdc.RegisterConcrete(MsgRevokeSettlerDelegation{}, "covenant/revokeSettlerDelegation", nil)
+	cdc.RegisterConcrete(MsgChangeParams{}, "covenant/changeParams", nil)
 }
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
+	names "github.com/cosmos/cosmos-academy/example-apps/covenant/x/names"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagName     = "name-to-claim"
+	flagNewOwner = "new-owner"
+)
+
+// ParseAddress reads an address given either as bech32 or as a name
+// registered in the names store storeName. Names are never as long as an
+// address.
+func ParseAddress(ctx context.CoreContext, storeName string, str string) (sdk.Address, error) {
+	str = strings.TrimSpace(str)
+	if len(str) > names.MaxNameLength {
+		return types.GetAddressBech32(str)
+	}
+	res, err := ctx.Query(names.NameKey(str), storeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no account is named %s", str)
+	}
+	return sdk.Address(res), nil
+}
+
+func ClaimNameTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "claim_name",
+		Short: "Claim an unused name for your account",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			name := viper.GetString(flagName)
+			if len(name) == 0 {
+				return fmt.Errorf("specify the name with --name-to-claim")
+			}
+
+			msg := names.MsgClaimName{Owner: owner, Name: name}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Claimed name %s for %s\n", name, types.MustBech32ifyAddress(owner))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagName, "", "Name to claim")
+	return cmd
+}
+
+func TransferNameTxCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer_name",
+		Short: "Transfer your name to another account",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+			name := viper.GetString(flagName)
+			if len(name) == 0 {
+				return fmt.Errorf("specify the name with --name-to-claim")
+			}
+			newOwnerString := viper.GetString(flagNewOwner)
+			if len(newOwnerString) == 0 {
+				return fmt.Errorf("specify the new owner with --new-owner")
+			}
+			newOwner, err := ParseAddress(ctx, storeName, newOwnerString)
+			if err != nil {
+				return err
+			}
+
+			msg := names.MsgTransferName{Owner: owner, Name: name, NewOwner: newOwner}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Transferred name %s to %s\n", name, types.MustBech32ifyAddress(newOwner))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagName, "", "Name to transfer")
+	cmd.Flags().String(flagNewOwner, "", "Address or name of the new owner")
+	return cmd
+}
+
+// GetAddressCmd looks up the address that owns a name
+func GetAddressCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "address <name>",
+		Short: "Query the address that owns a name",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper()
+			addr, err := ParseAddress(ctx, storeName, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(types.MustBech32ifyAddress(addr))
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,26 @@
+package names
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Reserve errors 800 ~ 899
+const (
+	DefaultCodespace sdk.CodespaceType = 8
+
+	CodeInvalidName sdk.CodeType = 801
+	CodeNameTaken   sdk.CodeType = 802
+	CodeUnknownName sdk.CodeType = 803
+)
+
+func ErrInvalidName(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidName, msg)
+}
+
+func ErrNameTaken(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeNameTaken, msg)
+}
+
+func ErrUnknownName(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownName, msg)
+}
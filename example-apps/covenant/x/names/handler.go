@@ -0,0 +1,37 @@
+package names
+
+import (
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case MsgClaimName:
+			return handleMsgClaimName(ctx, k, msg)
+		case MsgTransferName:
+			return handleMsgTransferName(ctx, k, msg)
+		default:
+			errMsg := "Unrecognized Names Msg type: " + reflect.TypeOf(msg).Name()
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+func handleMsgClaimName(ctx sdk.Context, keeper Keeper, msg MsgClaimName) sdk.Result {
+	err := keeper.claimName(ctx, msg.Owner, msg.Name)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
+
+func handleMsgTransferName(ctx sdk.Context, keeper Keeper, msg MsgTransferName) sdk.Result {
+	err := keeper.transferName(ctx, msg.Owner, msg.Name, msg.NewOwner)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
@@ -0,0 +1,119 @@
+package names
+
+import (
+	"bytes"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+// NamedAccount is an account that carries a name, like types.AppAccount
+type NamedAccount interface {
+	auth.Account
+	GetName() string
+	SetName(string)
+}
+
+// Keeper keeps the name of an account on the account itself, and the reverse
+// lookup from name to address in its own store. The index is what makes names
+// unique.
+type Keeper struct {
+	storeKey      sdk.StoreKey
+	accountMapper auth.AccountMapper
+
+	codespace sdk.CodespaceType
+}
+
+func NewKeeper(key sdk.StoreKey, am auth.AccountMapper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{
+		storeKey:      key,
+		accountMapper: am,
+		codespace:     codespace,
+	}
+}
+
+func (keeper Keeper) claimName(ctx sdk.Context, owner sdk.Address, name string) sdk.Error {
+	if keeper.GetAddress(ctx, name) != nil {
+		return ErrNameTaken(keeper.codespace, fmt.Sprintf("name %s is already claimed", name))
+	}
+	return keeper.setName(ctx, owner, name)
+}
+
+func (keeper Keeper) transferName(ctx sdk.Context, owner sdk.Address, name string, newOwner sdk.Address) sdk.Error {
+	addr := keeper.GetAddress(ctx, name)
+	if addr == nil {
+		return ErrUnknownName(keeper.codespace, fmt.Sprintf("name %s is not claimed", name))
+	}
+	if !bytes.Equal(addr, owner) {
+		return sdk.ErrUnauthorized(fmt.Sprintf("name %s is owned by %s", name, addr))
+	}
+	ownerAcc, err := keeper.getNamedAccount(ctx, owner)
+	if err != nil {
+		return err
+	}
+	err = keeper.setName(ctx, newOwner, name)
+	if err != nil {
+		return err
+	}
+	ownerAcc.SetName("")
+	keeper.accountMapper.SetAccount(ctx, ownerAcc)
+	return nil
+}
+
+// setName names the account at addr and indexes the name. Any name the
+// account had before is released.
+func (keeper Keeper) setName(ctx sdk.Context, addr sdk.Address, name string) sdk.Error {
+	acc, err := keeper.getNamedAccount(ctx, addr)
+	if err != nil {
+		return err
+	}
+	store := ctx.KVStore(keeper.storeKey)
+	if old := acc.GetName(); len(old) != 0 {
+		store.Delete(NameKey(old))
+	}
+	acc.SetName(name)
+	keeper.accountMapper.SetAccount(ctx, acc)
+	store.Set(NameKey(name), addr)
+	return nil
+}
+
+func (keeper Keeper) getNamedAccount(ctx sdk.Context, addr sdk.Address) (NamedAccount, sdk.Error) {
+	acc, ok := keeper.accountMapper.GetAccount(ctx, addr).(NamedAccount)
+	if !ok {
+		return nil, sdk.ErrUnknownAddress(fmt.Sprintf("no account with a name at %s", addr))
+	}
+	return acc, nil
+}
+
+// InitGenesis indexes the names accounts were given in the genesis file
+func (keeper Keeper) InitGenesis(ctx sdk.Context, accounts []sdk.Address) error {
+	store := ctx.KVStore(keeper.storeKey)
+	for _, addr := range accounts {
+		acc, err := keeper.getNamedAccount(ctx, addr)
+		if err != nil || len(acc.GetName()) == 0 {
+			continue
+		}
+		if store.Has(NameKey(acc.GetName())) {
+			return fmt.Errorf("name %s is given to more than one genesis account", acc.GetName())
+		}
+		store.Set(NameKey(acc.GetName()), addr)
+	}
+	return nil
+}
+
+// GetAddress looks up the owner of a name, nil if it is unclaimed
+func (keeper Keeper) GetAddress(ctx sdk.Context, name string) sdk.Address {
+	store := ctx.KVStore(keeper.storeKey)
+	bz := store.Get(NameKey(name))
+	if bz == nil {
+		return nil
+	}
+	return sdk.Address(bz)
+}
+
+// NameKey is where the owner of a name is stored. It is exported so that
+// clients can query it.
+func NameKey(name string) []byte {
+	return []byte("names:" + name)
+}
@@ -0,0 +1,90 @@
+package names
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Limits on the length of a name. Names are shorter than any address string,
+// so the CLI can take either without ambiguity.
+const (
+	MinNameLength = 3
+	MaxNameLength = 32
+)
+
+// ValidateName checks that name is lowercase letters, digits and dashes and
+// starts with a letter
+func ValidateName(name string) sdk.Error {
+	if len(name) < MinNameLength || len(name) > MaxNameLength {
+		return ErrInvalidName(DefaultCodespace, fmt.Sprintf("names are %d to %d characters long", MinNameLength, MaxNameLength))
+	}
+	if name[0] < 'a' || name[0] > 'z' {
+		return ErrInvalidName(DefaultCodespace, "names start with a lowercase letter")
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return ErrInvalidName(DefaultCodespace, "names only hold lowercase letters, digits and dashes")
+		}
+	}
+	return nil
+}
+
+// MsgClaimName gives an unclaimed name to Owner. An owner has at most one
+// name, so claiming releases the name it held before.
+type MsgClaimName struct {
+	Owner sdk.Address `json:"owner"`
+	Name  string      `json:"name"`
+}
+
+func (mcn MsgClaimName) Type() string {
+	return "names"
+}
+
+func (mcn MsgClaimName) GetSignBytes() []byte {
+	b, _ := json.Marshal(mcn)
+	return b
+}
+
+func (mcn MsgClaimName) ValidateBasic() sdk.Error {
+	if len(mcn.Owner) == 0 {
+		return sdk.ErrInvalidAddress("owner address is empty")
+	}
+	return ValidateName(mcn.Name)
+}
+
+func (mcn MsgClaimName) GetSigners() []sdk.Address {
+	return []sdk.Address{mcn.Owner}
+}
+
+// MsgTransferName moves a name from Owner to NewOwner
+type MsgTransferName struct {
+	Owner    sdk.Address `json:"owner"`
+	Name     string      `json:"name"`
+	NewOwner sdk.Address `json:"new_owner"`
+}
+
+func (mtn MsgTransferName) Type() string {
+	return "names"
+}
+
+func (mtn MsgTransferName) GetSignBytes() []byte {
+	b, _ := json.Marshal(mtn)
+	return b
+}
+
+func (mtn MsgTransferName) ValidateBasic() sdk.Error {
+	if len(mtn.Owner) == 0 || len(mtn.NewOwner) == 0 {
+		return sdk.ErrInvalidAddress("owner addresses can't be empty")
+	}
+	if bytes.Equal(mtn.Owner, mtn.NewOwner) {
+		return sdk.ErrInvalidAddress("cannot transfer a name to yourself")
+	}
+	return ValidateName(mtn.Name)
+}
+
+func (mtn MsgTransferName) GetSigners() []sdk.Address {
+	return []sdk.Address{mtn.Owner}
+}
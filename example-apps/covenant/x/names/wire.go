@@ -0,0 +1,11 @@
+package names
+
+import (
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// Register concrete types on wire codec
+func RegisterWire(cdc *wire.Codec) {
+	cdc.RegisterConcrete(MsgClaimName{}, "names/claim", nil)
+	cdc.RegisterConcrete(MsgTransferName{}, "names/transfer", nil)
+}
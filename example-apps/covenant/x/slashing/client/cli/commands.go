@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-academy/example-apps/covenant/types"
+	slashing "github.com/cosmos/cosmos-academy/example-apps/covenant/x/slashing"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/wire"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagValidator = "validator"
+)
+
+// UnjailTxCmd gives your jailed validator its power back
+func UnjailTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unjail",
+		Short: "Unjail your validator once its jail time is over",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(authcmd.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			msg := slashing.MsgUnjail{ValidatorAddr: owner}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Validator unjailed: %s\n", types.MustBech32ifyAddress(owner))
+			return nil
+		},
+	}
+	return cmd
+}
+
+// GetSigningInfoCmd queries the liveness record of a validator
+func GetSigningInfoCmd(storeName string, cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signing_info",
+		Short: "Query the missed blocks and jail status of a validator",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper()
+
+			validatorString := viper.GetString(flagValidator)
+			if len(validatorString) == 0 {
+				return fmt.Errorf("specify the validator operator address with --validator")
+			}
+			owner, err := types.GetAddressBech32(validatorString)
+			if err != nil {
+				return err
+			}
+			addr, err := ctx.Query(slashing.OwnerKey(owner), storeName)
+			if err != nil {
+				return err
+			}
+			if len(addr) == 0 {
+				return fmt.Errorf("no validator is operated by %s", validatorString)
+			}
+			res, err := ctx.Query(slashing.SigningInfoKey(addr), storeName)
+			if err != nil {
+				return err
+			}
+			var info slashing.SigningInfo
+			if len(res) != 0 {
+				err = cdc.UnmarshalBinary(res, &info)
+				if err != nil {
+					return err
+				}
+			}
+
+			output, err := wire.MarshalJSONIndent(cdc, info)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+	cmd.Flags().String(flagValidator, "", "Bech32 address of the validator operator")
+	return cmd
+}
@@ -0,0 +1,31 @@
+package slashing
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Reserve errors 900 ~ 999
+const (
+	DefaultCodespace sdk.CodespaceType = 9
+
+	CodeUnknownValidator   sdk.CodeType = 901
+	CodeValidatorNotJailed sdk.CodeType = 902
+	CodeValidatorJailed    sdk.CodeType = 903
+	CodeInvalidParams      sdk.CodeType = 904
+)
+
+func ErrUnknownValidator(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeUnknownValidator, msg)
+}
+
+func ErrValidatorNotJailed(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeValidatorNotJailed, msg)
+}
+
+func ErrValidatorJailed(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeValidatorJailed, msg)
+}
+
+func ErrInvalidParams(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidParams, msg)
+}
@@ -0,0 +1,40 @@
+package slashing
+
+import (
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+)
+
+func NewHandler(k Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case MsgUnjail:
+			return handleMsgUnjail(ctx, k, msg)
+		default:
+			errMsg := "Unrecognized Slashing Msg type: " + reflect.TypeOf(msg).Name()
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+// NewStakeHandler wraps the stake handler to learn the Tendermint key of
+// every validator created, which is how validators are identified in blocks.
+func NewStakeHandler(k Keeper, stakeHandler sdk.Handler) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		res := stakeHandler(ctx, msg)
+		if msg, ok := msg.(stake.MsgCreateValidator); ok && res.IsOK() {
+			k.addValidator(ctx, msg.ValidatorAddr, msg.PubKey)
+		}
+		return res
+	}
+}
+
+func handleMsgUnjail(ctx sdk.Context, keeper Keeper, msg MsgUnjail) sdk.Result {
+	err := keeper.unjail(ctx, msg.ValidatorAddr)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{}
+}
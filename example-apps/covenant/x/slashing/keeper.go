@@ -0,0 +1,420 @@
+package slashing
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// Keeper tracks the signatures of validators by their Tendermint address. The
+// stake module of this SDK release can't jail or slash, so a jailed validator
+// is given zero power in the validator updates sent to Tendermint, and
+// slashing unbonds part of the operator's self-delegation and burns it.
+type Keeper struct {
+	storeKey     sdk.StoreKey
+	cdc          *wire.Codec
+	stakeKeeper  stake.Keeper
+	stakeHandler sdk.Handler
+	coinKeeper   bank.Keeper
+
+	codespace sdk.CodespaceType
+}
+
+func NewKeeper(cdc *wire.Codec, key sdk.StoreKey, sk stake.Keeper, ck bank.Keeper, codespace sdk.CodespaceType) Keeper {
+	return Keeper{
+		storeKey:     key,
+		cdc:          cdc,
+		stakeKeeper:  sk,
+		stakeHandler: stake.NewHandler(sk),
+		coinKeeper:   ck,
+		codespace:    codespace,
+	}
+}
+
+// validatorInfo links a Tendermint validator to its stake operator
+type validatorInfo struct {
+	Owner  sdk.Address   `json:"owner"`
+	PubKey crypto.PubKey `json:"pub_key"`
+	Power  int64         `json:"power"` // last power stake gave the validator
+}
+
+// SigningInfo is the liveness record of a validator
+type SigningInfo struct {
+	StartHeight         int64 `json:"start_height"`
+	IndexOffset         int64 `json:"index_offset"`
+	MissedBlocksCounter int64 `json:"missed_blocks_counter"`
+	Jailed              bool  `json:"jailed"`
+	JailedUntil         int64 `json:"jailed_until"`
+}
+
+// InitGenesis stores the params, indexes the genesis validators of stake and
+// restores the state of exported validators. Jailed validators get a zero
+// power update at the end of the first block.
+func (keeper Keeper) InitGenesis(ctx sdk.Context, data Genesis, validators []stake.Validator) error {
+	err := data.Params.Validate()
+	if err != nil {
+		return err
+	}
+	keeper.setParams(ctx, data.Params)
+	for _, validator := range validators {
+		keeper.addValidator(ctx, validator.Owner, validator.PubKey)
+	}
+
+	store := ctx.KVStore(keeper.storeKey)
+	for _, v := range data.Validators {
+		if v.PubKey == nil {
+			return fmt.Errorf("slashing genesis validator %s has no pub key", v.Owner)
+		}
+		addr := v.PubKey.Address()
+		keeper.addValidator(ctx, v.Owner, v.PubKey)
+		keeper.setValidatorInfo(ctx, addr, validatorInfo{Owner: v.Owner, PubKey: v.PubKey, Power: v.Power})
+		keeper.setSigningInfo(ctx, addr, v.SigningInfo)
+		for _, index := range v.MissedBlocks {
+			store.Set(missedBlockKey(addr, index), []byte{1})
+		}
+		if v.SigningInfo.Jailed {
+			keeper.addPendingUpdate(ctx, abci.Validator{PubKey: tmtypes.TM2PB.PubKey(v.PubKey), Power: 0})
+		}
+	}
+	return nil
+}
+
+// WriteGenesis returns the module state to export: the params and the
+// signing state of every validator
+func (keeper Keeper) WriteGenesis(ctx sdk.Context) Genesis {
+	store := ctx.KVStore(keeper.storeKey)
+	iter := store.Iterator(validatorKeyPrefix, prefixEnd(validatorKeyPrefix))
+	var validators []ValidatorGenesis
+	for ; iter.Valid(); iter.Next() {
+		addr := iter.Key()[len(validatorKeyPrefix):]
+		var info validatorInfo
+		keeper.cdc.UnmarshalBinary(iter.Value(), &info)
+		validators = append(validators, ValidatorGenesis{
+			Owner:        info.Owner,
+			PubKey:       info.PubKey,
+			Power:        info.Power,
+			SigningInfo:  keeper.GetSigningInfo(ctx, addr),
+			MissedBlocks: keeper.getMissedBlocks(ctx, addr),
+		})
+	}
+	iter.Close()
+	return Genesis{Params: keeper.GetParams(ctx), Validators: validators}
+}
+
+// getMissedBlocks returns the window indexes of the blocks addr missed
+func (keeper Keeper) getMissedBlocks(ctx sdk.Context, addr []byte) []int64 {
+	store := ctx.KVStore(keeper.storeKey)
+	prefix := missedBlockPrefix(addr)
+	iter := store.Iterator(prefix, prefixEnd(prefix))
+	var indexes []int64
+	for ; iter.Valid(); iter.Next() {
+		index, err := strconv.ParseInt(string(iter.Key()[len(prefix):]), 10, 64)
+		if err == nil {
+			indexes = append(indexes, index)
+		}
+	}
+	iter.Close()
+	return indexes
+}
+
+// BeginBlocker counts the blocks each validator missed in the last commit,
+// and punishes double signing
+func (keeper Keeper) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) {
+	for _, sv := range req.Validators {
+		keeper.handleValidatorSignature(ctx, sv.Validator.Address, sv.SignedLastBlock)
+	}
+	for _, evidence := range req.ByzantineValidators {
+		keeper.handleDoubleSign(ctx, evidence.Validator.Address, evidence.Height)
+	}
+}
+
+// EndBlocker takes the validator updates of stake, records the power of each
+// validator, zeroes it for jailed validators and adds the updates of
+// validators jailed or unjailed during the block.
+func (keeper Keeper) EndBlocker(ctx sdk.Context, updates []abci.Validator) []abci.Validator {
+	for i, update := range updates {
+		addr := keeper.getAddressByPubKey(ctx, update.PubKey)
+		if addr == nil {
+			continue
+		}
+		info, _ := keeper.getValidatorInfo(ctx, addr)
+		info.Power = update.Power
+		keeper.setValidatorInfo(ctx, addr, info)
+		if keeper.GetSigningInfo(ctx, addr).Jailed {
+			updates[i].Power = 0
+		}
+	}
+
+	store := ctx.KVStore(keeper.storeKey)
+	bz := store.Get(pendingUpdatesKey)
+	if bz != nil {
+		var pending []abci.Validator
+		keeper.cdc.UnmarshalBinary(bz, &pending)
+		for _, update := range pending {
+			updates = setUpdate(updates, update)
+		}
+		store.Delete(pendingUpdatesKey)
+	}
+	return updates
+}
+
+// setUpdate replaces the update of the same validator, if there is one
+func setUpdate(updates []abci.Validator, update abci.Validator) []abci.Validator {
+	for i, u := range updates {
+		if u.PubKey.Type == update.PubKey.Type && bytes.Equal(u.PubKey.Data, update.PubKey.Data) {
+			updates[i] = update
+			return updates
+		}
+	}
+	return append(updates, update)
+}
+
+// addValidator indexes a validator created in stake
+func (keeper Keeper) addValidator(ctx sdk.Context, owner sdk.Address, pubKey crypto.PubKey) {
+	addr := pubKey.Address()
+	keeper.setValidatorInfo(ctx, addr, validatorInfo{Owner: owner, PubKey: pubKey})
+	store := ctx.KVStore(keeper.storeKey)
+	store.Set(OwnerKey(owner), addr)
+	store.Set(pubKeyKey(tmtypes.TM2PB.PubKey(pubKey)), addr)
+}
+
+func (keeper Keeper) handleValidatorSignature(ctx sdk.Context, addr []byte, signed bool) {
+	params := keeper.GetParams(ctx)
+	if params.SignedBlocksWindow == 0 {
+		return
+	}
+	height := ctx.BlockHeight()
+	info := keeper.GetSigningInfo(ctx, addr)
+	if info.IndexOffset == 0 && info.StartHeight == 0 {
+		info.StartHeight = height
+	}
+
+	store := ctx.KVStore(keeper.storeKey)
+	index := info.IndexOffset % params.SignedBlocksWindow
+	info.IndexOffset++
+	missedBefore := store.Has(missedBlockKey(addr, index))
+	if !signed && !missedBefore {
+		store.Set(missedBlockKey(addr, index), []byte{1})
+		info.MissedBlocksCounter++
+	} else if signed && missedBefore {
+		store.Delete(missedBlockKey(addr, index))
+		info.MissedBlocksCounter--
+	}
+
+	maxMissed := params.SignedBlocksWindow - params.MinSignedPerWindow
+	if !info.Jailed && height >= info.StartHeight+params.SignedBlocksWindow && info.MissedBlocksCounter > maxMissed {
+		ctx.Logger().Info("Validator jailed for downtime", "validator", fmt.Sprintf("%X", addr), "missed", info.MissedBlocksCounter)
+		keeper.slash(ctx, addr, params.SlashFractionDowntime)
+		info = keeper.jail(ctx, addr, info, height+params.DowntimeJailDuration)
+	}
+	keeper.setSigningInfo(ctx, addr, info)
+}
+
+func (keeper Keeper) handleDoubleSign(ctx sdk.Context, addr []byte, infractionHeight int64) {
+	params := keeper.GetParams(ctx)
+	if params.MaxEvidenceAge != 0 && ctx.BlockHeight()-infractionHeight > params.MaxEvidenceAge {
+		return
+	}
+	ctx.Logger().Info("Validator jailed for double signing", "validator", fmt.Sprintf("%X", addr), "height", infractionHeight)
+	keeper.slash(ctx, addr, params.SlashFractionDoubleSign)
+	info := keeper.GetSigningInfo(ctx, addr)
+	jailedUntil := ctx.BlockHeight() + params.DoubleSignJailDuration
+	if info.Jailed && info.JailedUntil > jailedUntil {
+		return
+	}
+	keeper.setSigningInfo(ctx, addr, keeper.jail(ctx, addr, info, jailedUntil))
+}
+
+// jail zeroes the validator's power until the given height. The missed
+// blocks are cleared, so the validator starts afresh once unjailed.
+func (keeper Keeper) jail(ctx sdk.Context, addr []byte, info SigningInfo, until int64) SigningInfo {
+	if !info.Jailed {
+		validator, found := keeper.getValidatorInfo(ctx, addr)
+		if found {
+			keeper.addPendingUpdate(ctx, abci.Validator{PubKey: tmtypes.TM2PB.PubKey(validator.PubKey), Power: 0})
+		}
+	}
+	store := ctx.KVStore(keeper.storeKey)
+	iter := store.Iterator(missedBlockPrefix(addr), prefixEnd(missedBlockPrefix(addr)))
+	var keys [][]byte
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	iter.Close()
+	for _, key := range keys {
+		store.Delete(key)
+	}
+
+	info.Jailed = true
+	info.JailedUntil = until
+	info.MissedBlocksCounter = 0
+	return info
+}
+
+func (keeper Keeper) unjail(ctx sdk.Context, owner sdk.Address) sdk.Error {
+	store := ctx.KVStore(keeper.storeKey)
+	addr := store.Get(OwnerKey(owner))
+	if addr == nil {
+		return ErrUnknownValidator(keeper.codespace, fmt.Sprintf("no validator is operated by %s", owner))
+	}
+	info := keeper.GetSigningInfo(ctx, addr)
+	if !info.Jailed {
+		return ErrValidatorNotJailed(keeper.codespace, "validator is not jailed")
+	}
+	if ctx.BlockHeight() < info.JailedUntil {
+		return ErrValidatorJailed(keeper.codespace, fmt.Sprintf("validator is jailed until height %d", info.JailedUntil))
+	}
+	validator, _ := keeper.getValidatorInfo(ctx, addr)
+	keeper.addPendingUpdate(ctx, abci.Validator{PubKey: tmtypes.TM2PB.PubKey(validator.PubKey), Power: validator.Power})
+
+	info.Jailed = false
+	info.StartHeight = ctx.BlockHeight()
+	info.IndexOffset = 0
+	keeper.setSigningInfo(ctx, addr, info)
+	return nil
+}
+
+// slash unbonds fraction basis points of the operator's self-delegation and
+// burns the unbonded coins
+func (keeper Keeper) slash(ctx sdk.Context, addr []byte, fraction int64) {
+	validator, found := keeper.getValidatorInfo(ctx, addr)
+	if !found || fraction == 0 {
+		return
+	}
+	delegation, found := keeper.stakeKeeper.GetDelegation(ctx, validator.Owner, validator.Owner)
+	if !found {
+		return
+	}
+	shares := delegation.Shares.Mul(sdk.NewRat(fraction, MaxSlashFraction)).Evaluate()
+	if shares <= 0 {
+		return
+	}
+
+	before := keeper.coinKeeper.GetCoins(ctx, validator.Owner)
+	res := keeper.stakeHandler(ctx, stake.NewMsgUnbond(validator.Owner, validator.Owner, strconv.FormatInt(shares, 10)))
+	if !res.IsOK() {
+		ctx.Logger().Error("Could not slash validator", "validator", fmt.Sprintf("%X", addr), "log", res.Log)
+		return
+	}
+	slashed := keeper.coinKeeper.GetCoins(ctx, validator.Owner).Minus(before)
+	keeper.coinKeeper.SubtractCoins(ctx, validator.Owner, slashed)
+}
+
+func (keeper Keeper) addPendingUpdate(ctx sdk.Context, update abci.Validator) {
+	store := ctx.KVStore(keeper.storeKey)
+	var pending []abci.Validator
+	bz := store.Get(pendingUpdatesKey)
+	if bz != nil {
+		keeper.cdc.UnmarshalBinary(bz, &pending)
+	}
+	bz, _ = keeper.cdc.MarshalBinary(append(pending, update))
+	store.Set(pendingUpdatesKey, bz)
+}
+
+func (keeper Keeper) getAddressByPubKey(ctx sdk.Context, pubKey abci.PubKey) []byte {
+	store := ctx.KVStore(keeper.storeKey)
+	return store.Get(pubKeyKey(pubKey))
+}
+
+func (keeper Keeper) getValidatorInfo(ctx sdk.Context, addr []byte) (validatorInfo, bool) {
+	store := ctx.KVStore(keeper.storeKey)
+	bz := store.Get(validatorKey(addr))
+	if bz == nil {
+		return validatorInfo{}, false
+	}
+	var info validatorInfo
+	keeper.cdc.UnmarshalBinary(bz, &info)
+	return info, true
+}
+
+func (keeper Keeper) setValidatorInfo(ctx sdk.Context, addr []byte, info validatorInfo) {
+	store := ctx.KVStore(keeper.storeKey)
+	bz, _ := keeper.cdc.MarshalBinary(info)
+	store.Set(validatorKey(addr), bz)
+}
+
+// GetSigningInfo returns the liveness record of the validator with the
+// Tendermint address addr
+func (keeper Keeper) GetSigningInfo(ctx sdk.Context, addr []byte) SigningInfo {
+	store := ctx.KVStore(keeper.storeKey)
+	bz := store.Get(SigningInfoKey(addr))
+	var info SigningInfo
+	if bz != nil {
+		keeper.cdc.UnmarshalBinary(bz, &info)
+	}
+	return info
+}
+
+func (keeper Keeper) setSigningInfo(ctx sdk.Context, addr []byte, info SigningInfo) {
+	store := ctx.KVStore(keeper.storeKey)
+	bz, _ := keeper.cdc.MarshalBinary(info)
+	store.Set(SigningInfoKey(addr), bz)
+}
+
+// GetParams returns the current params
+func (keeper Keeper) GetParams(ctx sdk.Context) Params {
+	store := ctx.KVStore(keeper.storeKey)
+	bz := store.Get(paramsKey)
+	var params Params
+	if bz != nil {
+		keeper.cdc.UnmarshalBinary(bz, &params)
+	}
+	return params
+}
+
+func (keeper Keeper) setParams(ctx sdk.Context, params Params) {
+	store := ctx.KVStore(keeper.storeKey)
+	bz, _ := keeper.cdc.MarshalBinary(params)
+	store.Set(paramsKey, bz)
+}
+
+var (
+	paramsKey         = []byte("params")
+	pendingUpdatesKey = []byte("pendingUpdates")
+)
+
+// SigningInfoKey is where the signing info of a validator is stored. It is
+// exported so that clients can query it.
+func SigningInfoKey(addr []byte) []byte {
+	return append([]byte("signingInfos:"), addr...)
+}
+
+var validatorKeyPrefix = []byte("validators:")
+
+func validatorKey(addr []byte) []byte {
+	return append(append([]byte{}, validatorKeyPrefix...), addr...)
+}
+
+// OwnerKey is where the Tendermint address of the validator run by owner is
+// stored. It is exported so that clients can query it.
+func OwnerKey(owner sdk.Address) []byte {
+	return append([]byte("owners:"), owner...)
+}
+
+func pubKeyKey(pubKey abci.PubKey) []byte {
+	return append([]byte("pubKeys:"+pubKey.Type+":"), pubKey.Data...)
+}
+
+func missedBlockPrefix(addr []byte) []byte {
+	return append(append([]byte("missedBlocks:"), addr...), ':')
+}
+
+func missedBlockKey(addr []byte, index int64) []byte {
+	return strconv.AppendInt(missedBlockPrefix(addr), index, 10)
+}
+
+// prefixEnd is the first key after every key that starts with prefix
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	end[len(end)-1]++
+	return end
+}
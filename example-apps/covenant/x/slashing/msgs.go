@@ -0,0 +1,32 @@
+package slashing
+
+import (
+	"encoding/json"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MsgUnjail gives a jailed validator its power back once its jail time is over
+type MsgUnjail struct {
+	ValidatorAddr sdk.Address `json:"validator_addr"` // the stake operator
+}
+
+func (msg MsgUnjail) Type() string {
+	return "slashing"
+}
+
+func (msg MsgUnjail) GetSignBytes() []byte {
+	b, _ := json.Marshal(msg)
+	return b
+}
+
+func (msg MsgUnjail) ValidateBasic() sdk.Error {
+	if len(msg.ValidatorAddr) == 0 {
+		return sdk.ErrInvalidAddress("missing validator address")
+	}
+	return nil
+}
+
+func (msg MsgUnjail) GetSigners() []sdk.Address {
+	return []sdk.Address{msg.ValidatorAddr}
+}
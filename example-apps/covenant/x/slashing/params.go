@@ -0,0 +1,71 @@
+package slashing
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+// Params of downtime tracking and slashing. A zero SignedBlocksWindow turns
+// off downtime tracking, and zero slash fractions only jail.
+type Params struct {
+	SignedBlocksWindow      int64 `json:"signed_blocks_window"`
+	MinSignedPerWindow      int64 `json:"min_signed_per_window"`
+	DowntimeJailDuration    int64 `json:"downtime_jail_duration"`     // in blocks
+	DoubleSignJailDuration  int64 `json:"double_sign_jail_duration"`  // in blocks
+	MaxEvidenceAge          int64 `json:"max_evidence_age"`           // in blocks
+	SlashFractionDowntime   int64 `json:"slash_fraction_downtime"`    // in basis points
+	SlashFractionDoubleSign int64 `json:"slash_fraction_double_sign"` // in basis points
+}
+
+// MaxSlashFraction slashes the whole self-bond
+const MaxSlashFraction = 10000
+
+// DefaultParams jail a validator that signed less than half of the last 100
+// blocks for 1000 blocks
+func DefaultParams() Params {
+	return Params{
+		SignedBlocksWindow:      100,
+		MinSignedPerWindow:      50,
+		DowntimeJailDuration:    1000,
+		DoubleSignJailDuration:  100000,
+		MaxEvidenceAge:          10000,
+		SlashFractionDowntime:   100,
+		SlashFractionDoubleSign: 500,
+	}
+}
+
+// Validate checks that the params are consistent
+func (p Params) Validate() sdk.Error {
+	if p.SignedBlocksWindow < 0 || p.MinSignedPerWindow < 0 || p.MinSignedPerWindow > p.SignedBlocksWindow {
+		return ErrInvalidParams(DefaultCodespace, "min signed per window must be between 0 and the window")
+	}
+	if p.DowntimeJailDuration < 0 || p.DoubleSignJailDuration < 0 || p.MaxEvidenceAge < 0 {
+		return ErrInvalidParams(DefaultCodespace, "durations cannot be negative")
+	}
+	if p.SlashFractionDowntime < 0 || p.SlashFractionDowntime > MaxSlashFraction ||
+		p.SlashFractionDoubleSign < 0 || p.SlashFractionDoubleSign > MaxSlashFraction {
+		return ErrInvalidParams(DefaultCodespace, "slash fractions must be between 0 and 10000 basis points")
+	}
+	return nil
+}
+
+// Genesis is the slashing module's part of the genesis file. Validators holds
+// the state of validators exported from a running chain.
+type Genesis struct {
+	Params     Params             `json:"params"`
+	Validators []ValidatorGenesis `json:"validators,omitempty"`
+}
+
+// ValidatorGenesis is the exported state of one validator
+type ValidatorGenesis struct {
+	Owner        sdk.Address   `json:"owner"`
+	PubKey       crypto.PubKey `json:"pub_key"`
+	Power        int64         `json:"power"`
+	SigningInfo  SigningInfo   `json:"signing_info"`
+	MissedBlocks []int64       `json:"missed_blocks,omitempty"` // indexes in the signed blocks window
+}
+
+// DefaultGenesis uses the default params
+func DefaultGenesis() Genesis {
+	return Genesis{Params: DefaultParams()}
+}
@@ -0,0 +1,10 @@
+package slashing
+
+import (
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// Register concrete types on wire codec
+func RegisterWire(cdc *wire.Codec) {
+	cdc.RegisterConcrete(MsgUnjail{}, "slashing/unjail", nil)
+}
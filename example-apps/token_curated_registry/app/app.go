@@ -28,70 +28,59 @@ type RegistryApp struct {
 
 	cdc *amino.Codec
 
-	minDeposit int64
-
-	applyStage int64
-
-	commitStage int64
-
-	revealStage int64
-
-	dispensationPct float64
-
-	quorum float64
-
 	// keys to access the substores
 	capKeyMain     *sdk.KVStoreKey
 	capKeyAccount  *sdk.KVStoreKey
 	capKeyListings *sdk.KVStoreKey
 	capKeyBallots  *sdk.KVStoreKey
 	capKeyFees     *sdk.KVStoreKey
+	capKeyParams   *sdk.KVStoreKey
 
 	feeKeeper    auth.FeeCollectionKeeper
 
 	ballotKeeper dbl.BallotKeeper
 
+	paramsKeeper dbl.ParamsKeeper
+
 	// Manage addition and subtraction of account balances
 	accountMapper auth.AccountMapper
 	accountKeeper bank.Keeper
 }
 
-// Initializes New Registry App
-// CONTRACT: applystage >= commitstage + revealstage
-func NewRegistryApp(logger log.Logger, db dbm.DB, mindeposit int64, applystage int64, commitstage int64, revealstage int64, dispensationpct float64, _quorum float64) *RegistryApp {
+// Initializes New Registry App. Registry params are read from genesis.
+func NewRegistryApp(logger log.Logger, db dbm.DB) *RegistryApp {
 	cdc := MakeCodec()
 	var app = &RegistryApp{
-		BaseApp:         bam.NewBaseApp(appName, cdc, logger, db),
-		cdc:             cdc,
-		minDeposit:      mindeposit,
-		applyStage:      applystage,
-		commitStage:     commitstage,
-		revealStage:     revealstage,
-		dispensationPct: dispensationpct,
-		quorum:          _quorum,
-		capKeyMain:      sdk.NewKVStoreKey("main"),
-		capKeyAccount:   sdk.NewKVStoreKey("acc"),
-		capKeyFees:      sdk.NewKVStoreKey("fee"),
-		capKeyListings:  sdk.NewKVStoreKey("listings"),
-		capKeyBallots:   sdk.NewKVStoreKey("ballots"),
+		BaseApp:        bam.NewBaseApp(appName, cdc, logger, db),
+		cdc:            cdc,
+		capKeyMain:     sdk.NewKVStoreKey("main"),
+		capKeyAccount:  sdk.NewKVStoreKey("acc"),
+		capKeyFees:     sdk.NewKVStoreKey("fee"),
+		capKeyListings: sdk.NewKVStoreKey("listings"),
+		capKeyBallots:  sdk.NewKVStoreKey("ballots"),
+		capKeyParams:   sdk.NewKVStoreKey("params"),
 	}
 
 	app.feeKeeper = auth.NewFeeCollectionKeeper(cdc, app.capKeyFees)
 
 	app.ballotKeeper = dbl.NewBallotKeeper(app.capKeyListings, app.capKeyBallots, app.cdc)
+	app.paramsKeeper = dbl.NewParamsKeeper(app.capKeyParams, app.cdc)
 	app.accountMapper = auth.NewAccountMapper(app.cdc, app.capKeyAccount, &auth.BaseAccount{})
 	app.accountKeeper = bank.NewKeeper(app.accountMapper)
 
 	app.Router().
-		AddRoute("DeclareCandidacy", handle.NewCandidacyHandler(app.accountKeeper, app.ballotKeeper, app.minDeposit, app.applyStage)).
-		AddRoute("Challenge", handle.NewChallengeHandler(app.accountKeeper, app.ballotKeeper, app.commitStage, app.revealStage, app.minDeposit)).
-		AddRoute("Commit", handle.NewCommitHandler(app.cdc, app.ballotKeeper)).
-		AddRoute("Reveal", handle.NewRevealHandler(app.accountKeeper, app.ballotKeeper))
+		AddRoute("DeclareCandidacy", handle.NewCandidacyHandler(app.accountKeeper, app.ballotKeeper, app.paramsKeeper)).
+		AddRoute("Challenge", handle.NewChallengeHandler(app.accountKeeper, app.ballotKeeper, app.paramsKeeper)).
+		AddRoute("Commit", handle.NewCommitHandler(app.cdc, app.accountKeeper, app.ballotKeeper, app.paramsKeeper)).
+		AddRoute("Reveal", handle.NewRevealHandler(app.ballotKeeper)).
+		AddRoute("ExitListing", handle.NewExitListingHandler(app.accountKeeper, app.ballotKeeper)).
+		AddRoute("Deposit", handle.NewDepositHandler(app.accountKeeper, app.ballotKeeper)).
+		AddRoute("Withdraw", handle.NewWithdrawHandler(app.accountKeeper, app.ballotKeeper, app.paramsKeeper))
 		
 	app.SetTxDecoder(app.txDecoder)
 	app.SetInitChainer(app.initChainer)
 	app.SetEndBlocker(app.endBlocker)
-	app.MountStoresIAVL(app.capKeyMain, app.capKeyAccount, app.capKeyFees, app.capKeyListings, app.capKeyBallots)
+	app.MountStoresIAVL(app.capKeyMain, app.capKeyAccount, app.capKeyFees, app.capKeyListings, app.capKeyBallots, app.capKeyParams)
 	app.SetAnteHandler(auth.NewAnteHandler(app.accountMapper, app.feeKeeper))
 
 	err := app.LoadLatestVersion(app.capKeyMain)
@@ -120,19 +109,45 @@ func (app *RegistryApp) initChainer(ctx sdk.Context, req abci.RequestInitChain)
 		}
 		app.accountMapper.SetAccount(ctx, acc)
 	}
+
+	// Every node must agree on the params, so they only come from genesis
+	paramsErr := genesisState.Params.Validate()
+	if paramsErr != nil {
+		panic(paramsErr)
+	}
+	app.paramsKeeper.SetParams(ctx, genesisState.Params)
+
+	registryErr := genesisState.Registry.Validate()
+	if registryErr != nil {
+		panic(registryErr)
+	}
+	app.ballotKeeper.InitGenesis(ctx, genesisState.Registry)
 	return abci.ResponseInitChain{}
 }
 
-// EndBlocker finalizes the ballot at the head of the queue if it has passed reveal phase. It also distributes rewards.
+// EndBlocker finalizes every ballot in the queue that has passed reveal phase, up to MaxResolvePerBlock
+// ballots in queue order. The rest stay at the head of the queue for the next block. It also distributes rewards.
 func (app *RegistryApp) endBlocker(ctx sdk.Context, req abci.RequestEndBlock) (res abci.ResponseEndBlock) {
-	ballot := app.ballotKeeper.ProposalQueueHead(ctx)
+	params := app.paramsKeeper.GetParams(ctx)
 
-	if ctx.BlockHeight() < ballot.EndApplyBlockStamp || ballot.Identifier == "" {
-		return
+	matured := app.ballotKeeper.ProposalQueueMatured(ctx, ctx.BlockHeight())
+	if int64(len(matured)) > params.MaxResolvePerBlock {
+		matured = matured[:params.MaxResolvePerBlock]
 	}
+	for _, item := range matured {
+		app.ballotKeeper.ProposalQueueRemove(ctx, item.Identifier)
+		// The ballot of a queued candidate may have been deleted, e.g. when challenged under the min bond
+		ballot := app.ballotKeeper.GetBallot(ctx, item.Identifier)
+		if ballot.Identifier == "" {
+			continue
+		}
+		app.resolveBallot(ctx, ballot, params)
+	}
+	return abci.ResponseEndBlock{}
+}
 
-	app.ballotKeeper.ProposalQueuePop(ctx)
-
+// resolveBallot adds or removes the listing of a ballot that left the queue
+func (app *RegistryApp) resolveBallot(ctx sdk.Context, ballot tcr.Ballot, params tcr.Params) {
 	if !ballot.Active {
 		// Perhaps put in something other than 0 here
 		app.ballotKeeper.AddListing(ctx, ballot.Identifier, 0)
@@ -142,10 +157,10 @@ func (app *RegistryApp) endBlocker(ctx sdk.Context, req abci.RequestEndBlock) (r
 	total := ballot.Approve + ballot.Deny
 	var correctVote bool
 	var pool float64
-	if float64(ballot.Approve) / float64(total) > app.quorum {
+	if float64(ballot.Approve) / float64(total) > params.Quorum {
 		app.ballotKeeper.AddListing(ctx, ballot.Identifier, ballot.Approve)
 		// award proposer dispensationPct of challenger bond
-		reward := int64(float64(ballot.Bond) * app.dispensationPct)
+		reward := int64(float64(ballot.Bond) * params.DispensationPct)
 		app.accountKeeper.AddCoins(ctx, ballot.Owner, sdk.Coins{{"RegistryCoin", reward}})
 		correctVote = true
 		pool = float64(ballot.Approve)
@@ -154,14 +169,14 @@ func (app *RegistryApp) endBlocker(ctx sdk.Context, req abci.RequestEndBlock) (r
 		app.ballotKeeper.DeleteBallot(ctx, ballot.Identifier)
 
 		// award challenger his original bond + dispensationPct of challenger bond
-		reward := ballot.Bond + int64(float64(ballot.Bond) * app.dispensationPct)
+		reward := ballot.Bond + int64(float64(ballot.Bond) * params.DispensationPct)
 		app.accountKeeper.AddCoins(ctx, ballot.Challenger, sdk.Coins{{"RegistryCoin", reward}})
 
 		correctVote = false
 		pool = float64(ballot.Deny)
 	}
 
-	prefixKey := []byte(ballot.Identifier + "votes")
+	prefixKey := tcr.VotePrefix(ballot.Identifier)
 	store := ctx.KVStore(app.capKeyBallots)
 	iter := sdk.KVStorePrefixIterator(store, prefixKey)
 	
@@ -169,7 +184,7 @@ func (app *RegistryApp) endBlocker(ctx sdk.Context, req abci.RequestEndBlock) (r
 	var keys [][]byte
 	for iter.Valid() {
 		keys = append(keys, iter.Key())
-		index := len([]byte(ballot.Identifier + "votes"))
+		index := len(prefixKey)
 		owner := iter.Key()[index:]
 
 		vz := iter.Value()
@@ -177,7 +192,7 @@ func (app *RegistryApp) endBlocker(ctx sdk.Context, req abci.RequestEndBlock) (r
 		app.cdc.UnmarshalBinary(vz, vote)
 
 		if correctVote == vote.Choice {
-			reward := vote.Power + int64(float64(vote.Power) / pool * float64(ballot.Bond) * app.dispensationPct)
+			reward := vote.Power + int64(float64(vote.Power) / pool * float64(ballot.Bond) * params.DispensationPct)
 			app.accountKeeper.AddCoins(ctx, owner, sdk.Coins{{"RegistryCoin", reward}})
 		} else {
 			app.accountKeeper.AddCoins(ctx, owner, sdk.Coins{{"RegistryCoin", vote.Power}})
@@ -191,9 +206,13 @@ func (app *RegistryApp) endBlocker(ctx sdk.Context, req abci.RequestEndBlock) (r
 		store.Delete(k)
 	}
 
-	app.ballotKeeper.DeactivateBallot(ctx, ballot.Identifier)
+	// Commitments left have not been revealed in time
+	app.ballotKeeper.PurgeCommitments(ctx, app.accountKeeper, ballot.Identifier, params.UnrevealedPenaltyPct)
 
-	return abci.ResponseEndBlock{}
+	// A ballot that lost its challenge has been deleted
+	if correctVote {
+		app.ballotKeeper.DeactivateBallot(ctx, ballot.Identifier)
+	}
 }
 
 func (app *RegistryApp) txDecoder(txBytes []byte) (sdk.Tx, sdk.Error) {
@@ -233,6 +252,8 @@ func (app *RegistryApp) ExportAppStateJSON() (appState json.RawMessage, err erro
 
 	genState := tcr.GenesisState{
 		Accounts: accounts,
+		Params:   app.paramsKeeper.GetParams(ctx),
+		Registry: app.ballotKeeper.ExportGenesis(ctx),
 	}
 	return wire.MarshalJSONIndent(app.cdc, genState)
 }
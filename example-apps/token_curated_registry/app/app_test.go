@@ -14,6 +14,7 @@ import (
 	"os"
 	"testing"
 	"crypto/sha256"
+	"encoding/json"
 	"math/rand"
 	"fmt"
 
@@ -23,10 +24,28 @@ import (
 func newRegistryApp() *RegistryApp {
 	logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout)).With("module", "sdk/app")
 	db := dbm.NewMemDB()
-	return NewRegistryApp(logger, db, 100, 10, 10, 10, 0.5, 0.5)
+	return NewRegistryApp(logger, db)
+}
+
+var testParams = tcr.Params{
+	MinDeposit:      100,
+	ApplyStage:      10,
+	CommitStage:     5,
+	RevealStage:     5,
+	DispensationPct: 0.5,
+	Quorum:          0.5,
+
+	MaxResolvePerBlock: 2,
+
+	MinCommitStake:       0,
+	UnrevealedPenaltyPct: 0,
 }
 
 func setGenesis(rapp *RegistryApp, accs ...auth.BaseAccount) error {
+	return setGenesisParams(rapp, testParams, accs...)
+}
+
+func setGenesisParams(rapp *RegistryApp, params tcr.Params, accs ...auth.BaseAccount) error {
 	genaccs := make([]*tcr.GenesisAccount, len(accs))
 	for i, acc := range accs {
 		genaccs[i] = tcr.NewGenesisAccount(&acc)
@@ -34,6 +53,7 @@ func setGenesis(rapp *RegistryApp, accs ...auth.BaseAccount) error {
 
 	genesisState := tcr.GenesisState{
 		Accounts: genaccs,
+		Params:   params,
 	}
 
 	stateBytes, err := wire.MarshalJSONIndent(rapp.cdc, genesisState)
@@ -348,7 +368,7 @@ func TestChallengeWonFlow(t *testing.T) {
 	// Check ballot 1 updated correctly
 	ballot1 := rapp.ballotKeeper.GetBallot(ctx, "Unique registry listing 1")
 	require.True(t, ballot1.Active, "Ballot 1 unactivated")
-	require.Equal(t, int64(25), ballot1.EndApplyBlockStamp, "Ballot 1 end blockstamp not updated after challenge")
+	require.Equal(t, int64(15), ballot1.EndApplyBlockStamp, "Ballot 1 end blockstamp not updated after challenge")
 
 	// Check that candidate 2 is now at head of queue
 	head = rapp.ballotKeeper.ProposalQueueHead(ctx).Identifier
@@ -359,9 +379,9 @@ func TestChallengeWonFlow(t *testing.T) {
 
 	cdc := MakeCodec()
 
-	commitMsg1, nonce1 := makeCommitment(cdc, addr4, "Unique registry listing 1", true)
-	commitMsg2, nonce2 := makeCommitment(cdc, addr5, "Unique registry listing 1", false)
-	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing 1", true)
+	commitMsg1, nonce1 := makeCommitment(cdc, addr4, "Unique registry listing 1", true, 300)
+	commitMsg2, nonce2 := makeCommitment(cdc, addr5, "Unique registry listing 1", false, 100)
+	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing 1", true, 100)
 
 	commitTx1 := GenTx(t.Name(), commitMsg1, fee, priv4, 0)
 	commitTx2 := GenTx(t.Name(), commitMsg2, fee, priv5, 0)
@@ -381,11 +401,11 @@ func TestChallengeWonFlow(t *testing.T) {
 	rapp.Commit()
 
 	// Move to reveal phase
-	header.Height = 16
+	header.Height = 12
 
-	revealMsg1 := tcr.NewRevealMsg(addr4, "Unique registry listing 1", true, nonce1, sdk.Coin{"RegistryCoin", 300})
-	revealMsg2 := tcr.NewRevealMsg(addr5, "Unique registry listing 1", false, nonce2, sdk.Coin{"RegistryCoin", 100})
-	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing 1", true, nonce3, sdk.Coin{"RegistryCoin", 100})
+	revealMsg1 := tcr.NewRevealMsg(addr4, "Unique registry listing 1", true, nonce1)
+	revealMsg2 := tcr.NewRevealMsg(addr5, "Unique registry listing 1", false, nonce2)
+	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing 1", true, nonce3)
 
 	
 	revealTx1 := GenTx(t.Name(), revealMsg1, fee, priv4, 1)
@@ -567,7 +587,7 @@ func TestChallengeLostFlow(t *testing.T) {
 	// Check ballot 1 updated correctly
 	ballot1 := rapp.ballotKeeper.GetBallot(ctx, "Unique registry listing 1")
 	require.True(t, ballot1.Active, "Ballot 1 unactivated")
-	require.Equal(t, int64(25), ballot1.EndApplyBlockStamp, "Ballot 1 end blockstamp not updated after challenge")
+	require.Equal(t, int64(15), ballot1.EndApplyBlockStamp, "Ballot 1 end blockstamp not updated after challenge")
 
 	// Check that candidate 2 is now at head of queue
 	head = rapp.ballotKeeper.ProposalQueueHead(ctx).Identifier
@@ -578,9 +598,9 @@ func TestChallengeLostFlow(t *testing.T) {
 
 	cdc := MakeCodec()
 
-	commitMsg1, nonce1 := makeCommitment(cdc, addr4, "Unique registry listing 1", false)
-	commitMsg2, nonce2 := makeCommitment(cdc, addr5, "Unique registry listing 1", false)
-	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing 1", true)
+	commitMsg1, nonce1 := makeCommitment(cdc, addr4, "Unique registry listing 1", false, 100)
+	commitMsg2, nonce2 := makeCommitment(cdc, addr5, "Unique registry listing 1", false, 300)
+	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing 1", true, 100)
 
 	commitTx1 := GenTx(t.Name(), commitMsg1, fee, priv4, 0)
 	commitTx2 := GenTx(t.Name(), commitMsg2, fee, priv5, 0)
@@ -600,11 +620,11 @@ func TestChallengeLostFlow(t *testing.T) {
 	rapp.Commit()
 
 	// Move to reveal phase
-	header.Height = 16
+	header.Height = 12
 
-	revealMsg1 := tcr.NewRevealMsg(addr4, "Unique registry listing 1", false, nonce1, sdk.Coin{"RegistryCoin", 100})
-	revealMsg2 := tcr.NewRevealMsg(addr5, "Unique registry listing 1", false, nonce2, sdk.Coin{"RegistryCoin", 300})
-	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing 1", true, nonce3, sdk.Coin{"RegistryCoin", 100})
+	revealMsg1 := tcr.NewRevealMsg(addr4, "Unique registry listing 1", false, nonce1)
+	revealMsg2 := tcr.NewRevealMsg(addr5, "Unique registry listing 1", false, nonce2)
+	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing 1", true, nonce3)
 
 	revealTx1 := GenTx(t.Name(), revealMsg1, fee, priv4, 1)
 	revealTx2 := GenTx(t.Name(), revealMsg2, fee, priv5, 1)
@@ -767,7 +787,7 @@ func TestChallengeExistWonFlow(t *testing.T) {
 	// Check ballot 1 updated correctly
 	ballot1 := rapp.ballotKeeper.GetBallot(ctx, "Unique registry listing")
 	require.True(t, ballot1.Active, "Ballot unactivated")
-	require.Equal(t, int64(30), ballot1.EndApplyBlockStamp, "Ballot end blockstamp not updated after challenge")
+	require.Equal(t, int64(20), ballot1.EndApplyBlockStamp, "Ballot end blockstamp not updated after challenge")
 	// Listing should not be removed from registry after challenge
 	require.Equal(t, "Unique registry listing", rapp.ballotKeeper.GetListing(ctx, "Unique registry listing").Identifier, "Candidate not added to registry after application phase")
 
@@ -780,9 +800,9 @@ func TestChallengeExistWonFlow(t *testing.T) {
 
 	cdc := MakeCodec()
 
-	commitMsg1, nonce1 := makeCommitment(cdc, addr3, "Unique registry listing", true)
-	commitMsg2, nonce2 := makeCommitment(cdc, addr4, "Unique registry listing", false)
-	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing", true)
+	commitMsg1, nonce1 := makeCommitment(cdc, addr3, "Unique registry listing", true, 300)
+	commitMsg2, nonce2 := makeCommitment(cdc, addr4, "Unique registry listing", false, 100)
+	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing", true, 100)
 
 	commitTx1 := GenTx(t.Name(), commitMsg1, fee, priv3, 0)
 	commitTx2 := GenTx(t.Name(), commitMsg2, fee, priv4, 0)
@@ -801,12 +821,12 @@ func TestChallengeExistWonFlow(t *testing.T) {
 	rapp.EndBlock(abci.RequestEndBlock{})
 	rapp.Commit()
 
-	// Move to reveal phase
-	header.Height = 30
+	// Move to end of reveal phase
+	header.Height = 20
 
-	revealMsg1 := tcr.NewRevealMsg(addr3, "Unique registry listing", true, nonce1, sdk.Coin{"RegistryCoin", 300})
-	revealMsg2 := tcr.NewRevealMsg(addr4, "Unique registry listing", false, nonce2, sdk.Coin{"RegistryCoin", 100})
-	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing", true, nonce3, sdk.Coin{"RegistryCoin", 100})
+	revealMsg1 := tcr.NewRevealMsg(addr3, "Unique registry listing", true, nonce1)
+	revealMsg2 := tcr.NewRevealMsg(addr4, "Unique registry listing", false, nonce2)
+	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing", true, nonce3)
 
 	
 	revealTx1 := GenTx(t.Name(), revealMsg1, fee, priv3, 1)
@@ -953,7 +973,7 @@ func TestChallengeExistLostFlow(t *testing.T) {
 	// Check ballot 1 updated correctly
 	ballot1 := rapp.ballotKeeper.GetBallot(ctx, "Unique registry listing")
 	require.True(t, ballot1.Active, "Ballot unactivated")
-	require.Equal(t, int64(30), ballot1.EndApplyBlockStamp, "Ballot end blockstamp not updated after challenge")
+	require.Equal(t, int64(20), ballot1.EndApplyBlockStamp, "Ballot end blockstamp not updated after challenge")
 	// Listing should not be removed from registry after challenge
 	require.Equal(t, "Unique registry listing", rapp.ballotKeeper.GetListing(ctx, "Unique registry listing").Identifier, "Candidate not added to registry after application phase")
 
@@ -966,9 +986,9 @@ func TestChallengeExistLostFlow(t *testing.T) {
 
 	cdc := MakeCodec()
 
-	commitMsg1, nonce1 := makeCommitment(cdc, addr3, "Unique registry listing", false)
-	commitMsg2, nonce2 := makeCommitment(cdc, addr4, "Unique registry listing", false)
-	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing", true)
+	commitMsg1, nonce1 := makeCommitment(cdc, addr3, "Unique registry listing", false, 300)
+	commitMsg2, nonce2 := makeCommitment(cdc, addr4, "Unique registry listing", false, 100)
+	commitMsg3, nonce3 := makeCommitment(cdc, addr1, "Unique registry listing", true, 100)
 
 	commitTx1 := GenTx(t.Name(), commitMsg1, fee, priv3, 0)
 	commitTx2 := GenTx(t.Name(), commitMsg2, fee, priv4, 0)
@@ -987,12 +1007,12 @@ func TestChallengeExistLostFlow(t *testing.T) {
 	rapp.EndBlock(abci.RequestEndBlock{})
 	rapp.Commit()
 
-	// Move to reveal phase
-	header.Height = 30
+	// Move to end of reveal phase
+	header.Height = 20
 
-	revealMsg1 := tcr.NewRevealMsg(addr3, "Unique registry listing", false, nonce1, sdk.Coin{"RegistryCoin", 300})
-	revealMsg2 := tcr.NewRevealMsg(addr4, "Unique registry listing", false, nonce2, sdk.Coin{"RegistryCoin", 100})
-	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing", true, nonce3, sdk.Coin{"RegistryCoin", 100})
+	revealMsg1 := tcr.NewRevealMsg(addr3, "Unique registry listing", false, nonce1)
+	revealMsg2 := tcr.NewRevealMsg(addr4, "Unique registry listing", false, nonce2)
+	revealMsg3 := tcr.NewRevealMsg(addr1, "Unique registry listing", true, nonce3)
 
 	
 	revealTx1 := GenTx(t.Name(), revealMsg1, fee, priv3, 1)
@@ -1033,7 +1053,7 @@ func TestChallengeExistLostFlow(t *testing.T) {
 	require.True(t, rapp.accountKeeper.HasCoins(ctx, addr3, sdk.Coins{{"RegistryCoin", 300}}), fmt.Sprintf("Challenge has: %+v", rapp.accountKeeper.GetCoins(ctx, addr3)))
 }
 
-func makeCommitment(cdc *amino.Codec, owner sdk.Address, identifier string, vote bool) (commitMsg tcr.CommitMsg, nonce []byte) {
+func makeCommitment(cdc *amino.Codec, owner sdk.Address, identifier string, vote bool, bond int64) (commitMsg tcr.CommitMsg, nonce []byte) {
 	hasher := sha256.New()
 	vz, _ := cdc.MarshalBinary(vote)
 	hasher.Sum(vz)
@@ -1044,7 +1064,7 @@ func makeCommitment(cdc *amino.Codec, owner sdk.Address, identifier string, vote
 
 	commitment := hasher.Sum(nonce)
 
-	commitMsg = tcr.NewCommitMsg(owner, identifier, commitment)
+	commitMsg = tcr.NewCommitMsg(owner, identifier, commitment, sdk.Coin{"RegistryCoin", bond})
 	return
 }
 
@@ -1058,3 +1078,431 @@ func GenTx(chainID string, msg sdk.Msg, fee auth.StdFee, priv crypto.PrivKey, se
 		seq,
 	}})
 }
+
+func TestQuery(t *testing.T) {
+	rapp := newRegistryApp()
+
+	privKey := utils.GeneratePrivKey()
+	addr := privKey.PubKey().Address()
+	acc := auth.NewBaseAccountWithAddress(addr)
+
+	acc.SetCoins([]sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 200,
+	}})
+
+	err := setGenesis(rapp, acc)
+	if err != nil {
+		panic(err)
+	}
+
+	msg1 := tcr.NewDeclareCandidacyMsg(addr, "Unique registry listing 1", sdk.Coin{"RegistryCoin", 100})
+	msg2 := tcr.NewDeclareCandidacyMsg(addr, "Unique registry listing 2", sdk.Coin{"RegistryCoin", 100})
+
+	fee := auth.StdFee{Gas: 10000000}
+
+	header := abci.Header{AppHash: []byte("apphash"), ChainID: t.Name()}
+	rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+	dres := rapp.Deliver(GenTx(t.Name(), msg2, fee, privKey, 0))
+	require.Equal(t, sdk.CodeType(0), sdk.CodeType(dres.Code), dres.Log)
+	header.Height = 1
+	rapp.EndBlock(abci.RequestEndBlock{})
+	rapp.Commit()
+
+	rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+	dres = rapp.Deliver(GenTx(t.Name(), msg1, fee, privKey, 1))
+	require.Equal(t, sdk.CodeType(0), sdk.CodeType(dres.Code), dres.Log)
+	rapp.EndBlock(abci.RequestEndBlock{})
+	rapp.Commit()
+
+	// Candidates are queued in order of resolution
+	res := rapp.Query(abci.RequestQuery{Path: tcr.QueryQueue})
+	require.Equal(t, uint32(0), res.Code, res.Log)
+	var queue []tcr.QueueItem
+	require.Nil(t, rapp.cdc.UnmarshalJSON(res.Value, &queue))
+	require.Equal(t, []tcr.QueueItem{{"Unique registry listing 2", 10}, {"Unique registry listing 1", 11}}, queue)
+
+	res = rapp.Query(abci.RequestQuery{Path: tcr.QueryBallot, Data: []byte("Unique registry listing 1")})
+	require.Equal(t, uint32(0), res.Code, res.Log)
+	var status tcr.BallotStatus
+	require.Nil(t, rapp.cdc.UnmarshalJSON(res.Value, &status))
+	require.Equal(t, addr, status.Owner)
+	require.Equal(t, int64(11), status.EndApplyBlockStamp)
+	require.Equal(t, tcr.PhaseApply, status.Phase)
+
+	res = rapp.Query(abci.RequestQuery{Path: tcr.QueryListing, Data: []byte("Unique registry listing 1")})
+	require.NotEqual(t, uint32(0), res.Code, "Candidate should not be listed yet")
+
+	// Mine blocks until both candidates are listed
+	for i := 2; i <= 11; i++ {
+		header.Height = int64(i)
+		rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+		rapp.EndBlock(abci.RequestEndBlock{})
+		rapp.Commit()
+	}
+
+	res = rapp.Query(abci.RequestQuery{Path: tcr.QueryListing, Data: []byte("Unique registry listing 1")})
+	require.Equal(t, uint32(0), res.Code, res.Log)
+	var listing tcr.Listing
+	require.Nil(t, rapp.cdc.UnmarshalJSON(res.Value, &listing))
+	require.Equal(t, tcr.NewListing("Unique registry listing 1", 0), listing)
+
+	params, _ := json.Marshal(tcr.ListingsParams{Page: 1, Limit: 1})
+	res = rapp.Query(abci.RequestQuery{Path: tcr.QueryListings, Data: params})
+	require.Equal(t, uint32(0), res.Code, res.Log)
+	var listings []tcr.Listing
+	require.Nil(t, rapp.cdc.UnmarshalJSON(res.Value, &listings))
+	require.Equal(t, []tcr.Listing{tcr.NewListing("Unique registry listing 2", 0)}, listings)
+
+	res = rapp.Query(abci.RequestQuery{Path: tcr.QueryQueue})
+	require.Equal(t, uint32(0), res.Code, res.Log)
+	require.Nil(t, rapp.cdc.UnmarshalJSON(res.Value, &queue))
+	require.Equal(t, 0, len(queue))
+}
+
+func TestGenesisParams(t *testing.T) {
+	rapp := newRegistryApp()
+
+	// Params breaking the applystage >= commitstage + revealstage contract are rejected
+	params := testParams
+	params.ApplyStage = 9
+	stateBytes, err := wire.MarshalJSONIndent(rapp.cdc, tcr.GenesisState{Params: params})
+	require.Nil(t, err)
+	require.Panics(t, func() {
+		rapp.InitChain(abci.RequestInitChain{AppStateBytes: stateBytes})
+	})
+
+	// So are penalties outside of [0, 1], and the default penalty is partial
+	params = testParams
+	params.UnrevealedPenaltyPct = 1.5
+	require.NotNil(t, params.Validate())
+	require.Nil(t, tcr.DefaultParams().Validate())
+	require.True(t, tcr.DefaultParams().UnrevealedPenaltyPct < 1)
+
+	rapp = newRegistryApp()
+	err = setGenesis(rapp)
+	require.Nil(t, err)
+
+	ctx := rapp.NewContext(true, abci.Header{})
+	require.Equal(t, testParams, rapp.paramsKeeper.GetParams(ctx))
+
+	// Params are exported with the rest of the genesis state
+	appState, err := rapp.ExportAppStateJSON()
+	require.Nil(t, err)
+	var exported tcr.GenesisState
+	err = rapp.cdc.UnmarshalJSON(appState, &exported)
+	require.Nil(t, err)
+	require.Equal(t, testParams, exported.Params)
+}
+
+func TestExportImport(t *testing.T) {
+	rapp := newRegistryApp()
+
+	privs := make([]crypto.PrivKey, 3)
+	accs := make([]auth.BaseAccount, 3)
+	for i := range privs {
+		privs[i] = utils.GeneratePrivKey()
+		accs[i] = auth.NewBaseAccountWithAddress(privs[i].PubKey().Address())
+		accs[i].SetCoins([]sdk.Coin{{"RegistryCoin", 300}})
+	}
+	addr1, addr2, addr3 := accs[0].Address, accs[1].Address, accs[2].Address
+
+	err := setGenesis(rapp, accs...)
+	if err != nil {
+		panic(err)
+	}
+
+	fee := auth.StdFee{Gas: 10000000}
+	header := abci.Header{AppHash: []byte("apphash"), ChainID: t.Name()}
+	deliverBlock := func(height int64, txs ...auth.StdTx) {
+		header.Height = height
+		rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+		for _, tx := range txs {
+			res := rapp.Deliver(tx)
+			require.True(t, res.IsOK(), res.Log)
+		}
+		rapp.EndBlock(abci.RequestEndBlock{})
+		rapp.Commit()
+	}
+
+	// Listing 1 is listed, then challenged while listing 2 applies
+	declareMsg1 := tcr.NewDeclareCandidacyMsg(addr1, "Unique registry listing 1", sdk.Coin{"RegistryCoin", 100})
+	deliverBlock(0, GenTx(t.Name(), declareMsg1, fee, privs[0], 0))
+	deliverBlock(10)
+
+	declareMsg2 := tcr.NewDeclareCandidacyMsg(addr2, "Unique registry listing 2", sdk.Coin{"RegistryCoin", 100})
+	challengeMsg := tcr.NewChallengeMsg(addr3, "Unique registry listing 1", sdk.Coin{"RegistryCoin", 100})
+	deliverBlock(10, GenTx(t.Name(), declareMsg2, fee, privs[1], 0), GenTx(t.Name(), challengeMsg, fee, privs[2], 0))
+
+	cdc := MakeCodec()
+	commitMsg1, nonce1 := makeCommitment(cdc, addr1, "Unique registry listing 1", true, 100)
+	commitMsg2, _ := makeCommitment(cdc, addr2, "Unique registry listing 1", false, 100)
+	deliverBlock(11, GenTx(t.Name(), commitMsg1, fee, privs[0], 1), GenTx(t.Name(), commitMsg2, fee, privs[1], 1))
+
+	// Only addr1 reveals, so there is both a vote and a commitment in the store
+	revealMsg := tcr.NewRevealMsg(addr1, "Unique registry listing 1", true, nonce1)
+	deliverBlock(16, GenTx(t.Name(), revealMsg, fee, privs[0], 2))
+
+	appState, err := rapp.ExportAppStateJSON()
+	require.Nil(t, err)
+	var exported tcr.GenesisState
+	err = rapp.cdc.UnmarshalJSON(appState, &exported)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(exported.Registry.Listings))
+	require.Equal(t, 2, len(exported.Registry.Ballots))
+	require.Equal(t, 1, len(exported.Registry.Commitments))
+	require.Equal(t, 1, len(exported.Registry.Votes))
+	require.Equal(t, []tcr.QueueItem{{"Unique registry listing 1", 20}, {"Unique registry listing 2", 20}}, exported.Registry.CandidateQueue)
+
+	// A new chain started from the export has the same registry stores
+	rapp2 := newRegistryApp()
+	rapp2.InitChain(abci.RequestInitChain{AppStateBytes: appState})
+	rapp2.Commit()
+	for _, key := range []*sdk.KVStoreKey{rapp.capKeyListings, rapp.capKeyBallots, rapp.capKeyParams} {
+		require.Equal(t, storeHash(rapp, key), storeHash(rapp2, key), fmt.Sprintf("Store %s differs after import", key.Name()))
+	}
+
+	// The candidate queue must match the ballots
+	exported.Registry.CandidateQueue[0].EndHeight = 19
+	stateBytes, err := wire.MarshalJSONIndent(rapp.cdc, exported)
+	require.Nil(t, err)
+	require.Panics(t, func() {
+		newRegistryApp().InitChain(abci.RequestInitChain{AppStateBytes: stateBytes})
+	})
+
+	queue := exported.Registry.CandidateQueue
+	exported.Registry.CandidateQueue = queue[1:]
+	stateBytes, err = wire.MarshalJSONIndent(rapp.cdc, exported)
+	require.Nil(t, err)
+	require.Panics(t, func() {
+		newRegistryApp().InitChain(abci.RequestInitChain{AppStateBytes: stateBytes})
+	}, "Challenged ballot missing from the queue was accepted")
+
+	exported.Registry.CandidateQueue = queue[:1]
+	stateBytes, err = wire.MarshalJSONIndent(rapp.cdc, exported)
+	require.Nil(t, err)
+	require.Panics(t, func() {
+		newRegistryApp().InitChain(abci.RequestInitChain{AppStateBytes: stateBytes})
+	}, "Unlisted candidate missing from the queue was accepted")
+
+	// Keys the export doesn't know, like the empty ballot a lost challenge
+	// used to leave behind, are left out instead of failing the export
+	header.Height = 17
+	rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+	ctx := rapp.NewContext(false, header)
+	stray, _ := rapp.cdc.MarshalBinary(tcr.Ballot{})
+	ctx.KVStore(rapp.capKeyBallots).Set([]byte("Unique registry listing 3"), stray)
+	ctx.KVStore(rapp.capKeyBallots).Set([]byte("junk"), []byte{1})
+	rapp.EndBlock(abci.RequestEndBlock{})
+	rapp.Commit()
+	appState, err = rapp.ExportAppStateJSON()
+	require.Nil(t, err)
+	err = rapp.cdc.UnmarshalJSON(appState, &exported)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(exported.Registry.Ballots))
+}
+
+func TestExportImportRedeclared(t *testing.T) {
+	rapp := newRegistryApp()
+
+	privKey := utils.GeneratePrivKey()
+	addr := privKey.PubKey().Address()
+	acc := auth.NewBaseAccountWithAddress(addr)
+	acc.SetCoins([]sdk.Coin{{"RegistryCoin", 300}})
+
+	err := setGenesis(rapp, acc)
+	if err != nil {
+		panic(err)
+	}
+
+	fee := auth.StdFee{Gas: 10000000}
+	header := abci.Header{AppHash: []byte("apphash"), ChainID: t.Name()}
+	deliverBlock := func(height int64, txs ...auth.StdTx) {
+		header.Height = height
+		rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+		for _, tx := range txs {
+			res := rapp.Deliver(tx)
+			require.True(t, res.IsOK(), res.Log)
+		}
+		rapp.EndBlock(abci.RequestEndBlock{})
+		rapp.Commit()
+	}
+
+	// Declaring the candidate again restarts its apply stage
+	declareMsg := tcr.NewDeclareCandidacyMsg(addr, "Unique registry listing", sdk.Coin{"RegistryCoin", 100})
+	deliverBlock(0, GenTx(t.Name(), declareMsg, fee, privKey, 0))
+	deliverBlock(3, GenTx(t.Name(), declareMsg, fee, privKey, 1))
+
+	appState, err := rapp.ExportAppStateJSON()
+	require.Nil(t, err)
+	var exported tcr.GenesisState
+	err = rapp.cdc.UnmarshalJSON(appState, &exported)
+	require.Nil(t, err)
+	require.Equal(t, int64(13), exported.Registry.Ballots[0].EndApplyBlockStamp)
+	require.Equal(t, []tcr.QueueItem{{"Unique registry listing", 13}}, exported.Registry.CandidateQueue)
+
+	// The export is accepted by a new chain
+	rapp2 := newRegistryApp()
+	rapp2.InitChain(abci.RequestInitChain{AppStateBytes: appState})
+	rapp2.Commit()
+	require.Equal(t, storeHash(rapp, rapp.capKeyBallots), storeHash(rapp2, rapp2.capKeyBallots), "Ballots differ after import")
+}
+
+// storeHash hashes every key and value in the committed store
+func storeHash(rapp *RegistryApp, key *sdk.KVStoreKey) []byte {
+	ctx := rapp.NewContext(true, abci.Header{})
+	iter := ctx.KVStore(key).Iterator(nil, nil)
+	defer iter.Close()
+
+	hasher := sha256.New()
+	for ; iter.Valid(); iter.Next() {
+		hasher.Write(iter.Key())
+		hasher.Write(iter.Value())
+	}
+	return hasher.Sum(nil)
+}
+
+func TestResolveMaturedBallots(t *testing.T) {
+	rapp := newRegistryApp()
+
+	privKey := utils.GeneratePrivKey()
+	addr := privKey.PubKey().Address()
+	acc := auth.NewBaseAccountWithAddress(addr)
+	acc.SetCoins([]sdk.Coin{{"RegistryCoin", 300}})
+
+	err := setGenesis(rapp, acc)
+	if err != nil {
+		panic(err)
+	}
+
+	// Three candidates mature at the same height
+	fee := auth.StdFee{Gas: 10000000}
+	header := abci.Header{AppHash: []byte("apphash"), ChainID: t.Name()}
+	rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+	for i, identifier := range []string{"c", "a", "b"} {
+		msg := tcr.NewDeclareCandidacyMsg(addr, identifier, sdk.Coin{"RegistryCoin", 100})
+		res := rapp.Deliver(GenTx(t.Name(), msg, fee, privKey, int64(i)))
+		require.True(t, res.IsOK(), res.Log)
+	}
+	rapp.EndBlock(abci.RequestEndBlock{})
+	rapp.Commit()
+
+	// Only MaxResolvePerBlock of them are listed at their end height, in identifier order
+	header.Height = 10
+	rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+	rapp.EndBlock(abci.RequestEndBlock{})
+	rapp.Commit()
+
+	ctx := rapp.NewContext(true, header)
+	require.Equal(t, "a", rapp.ballotKeeper.GetListing(ctx, "a").Identifier)
+	require.Equal(t, "b", rapp.ballotKeeper.GetListing(ctx, "b").Identifier)
+	require.Equal(t, tcr.Listing{}, rapp.ballotKeeper.GetListing(ctx, "c"))
+	require.Equal(t, []tcr.QueueItem{{"c", 10}}, rapp.ballotKeeper.ProposalQueueItems(ctx))
+
+	// The rest is listed in the next block
+	header.Height = 11
+	rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+	rapp.EndBlock(abci.RequestEndBlock{})
+	rapp.Commit()
+
+	ctx = rapp.NewContext(true, header)
+	require.Equal(t, "c", rapp.ballotKeeper.GetListing(ctx, "c").Identifier)
+	require.Equal(t, []tcr.QueueItem{}, rapp.ballotKeeper.ProposalQueueItems(ctx))
+
+	// A queued candidate whose ballot was deleted is dropped without a listing
+	header.Height = 12
+	rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+	ctx = rapp.NewContext(false, header)
+	rapp.ballotKeeper.ProposalQueuePush(ctx, "d", 12)
+	rapp.EndBlock(abci.RequestEndBlock{})
+	rapp.Commit()
+
+	ctx = rapp.NewContext(true, header)
+	require.Equal(t, tcr.Listing{}, rapp.ballotKeeper.GetListing(ctx, ""))
+	require.Equal(t, tcr.Listing{}, rapp.ballotKeeper.GetListing(ctx, "d"))
+	require.Equal(t, []tcr.QueueItem{}, rapp.ballotKeeper.ProposalQueueItems(ctx))
+}
+
+func TestUnrevealedCommitments(t *testing.T) {
+	rapp := newRegistryApp()
+
+	privs := make([]crypto.PrivKey, 4)
+	accs := make([]auth.BaseAccount, 4)
+	for i := range privs {
+		privs[i] = utils.GeneratePrivKey()
+		accs[i] = auth.NewBaseAccountWithAddress(privs[i].PubKey().Address())
+	}
+	accs[0].SetCoins([]sdk.Coin{{"RegistryCoin", 300}})
+	accs[1].SetCoins([]sdk.Coin{{"RegistryCoin", 300}})
+	accs[2].SetCoins([]sdk.Coin{{"RegistryCoin", 100}})
+	accs[3].SetCoins([]sdk.Coin{{"RegistryCoin", 100}})
+	addr1, addr2, addr3, addr4 := accs[0].Address, accs[1].Address, accs[2].Address, accs[3].Address
+
+	params := testParams
+	params.MinCommitStake = 20
+	params.UnrevealedPenaltyPct = 0.5
+	err := setGenesisParams(rapp, params, accs...)
+	if err != nil {
+		panic(err)
+	}
+
+	fee := auth.StdFee{Gas: 10000000}
+	header := abci.Header{AppHash: []byte("apphash"), ChainID: t.Name()}
+	deliverBlock := func(height int64, txs ...auth.StdTx) {
+		header.Height = height
+		rapp.BeginBlock(abci.RequestBeginBlock{Header: header})
+		for _, tx := range txs {
+			res := rapp.Deliver(tx)
+			require.True(t, res.IsOK(), res.Log)
+		}
+		rapp.EndBlock(abci.RequestEndBlock{})
+		rapp.Commit()
+	}
+
+	declareMsg := tcr.NewDeclareCandidacyMsg(addr1, "Unique registry listing", sdk.Coin{"RegistryCoin", 100})
+	deliverBlock(0, GenTx(t.Name(), declareMsg, fee, privs[0], 0))
+
+	challengeMsg := tcr.NewChallengeMsg(addr2, "Unique registry listing", sdk.Coin{"RegistryCoin", 100})
+	deliverBlock(1, GenTx(t.Name(), challengeMsg, fee, privs[1], 0))
+
+	// Both voters lock their voting stake
+	cdc := MakeCodec()
+	commitMsg1, nonce1 := makeCommitment(cdc, addr3, "Unique registry listing", true, 50)
+	commitMsg2, _ := makeCommitment(cdc, addr4, "Unique registry listing", false, 20)
+	deliverBlock(2, GenTx(t.Name(), commitMsg1, fee, privs[2], 0), GenTx(t.Name(), commitMsg2, fee, privs[3], 0))
+
+	ctx := rapp.NewContext(true, header)
+	require.True(t, rapp.accountKeeper.HasCoins(ctx, addr4, sdk.Coins{{"RegistryCoin", 80}}), "Commit stake not locked")
+	require.False(t, rapp.accountKeeper.HasCoins(ctx, addr4, sdk.Coins{{"RegistryCoin", 81}}), "Commit stake not locked")
+
+	// Only addr3 reveals. Its stake stays locked until the ballot is finalized
+	revealMsg := tcr.NewRevealMsg(addr3, "Unique registry listing", true, nonce1)
+	deliverBlock(7, GenTx(t.Name(), revealMsg, fee, privs[2], 1))
+
+	ctx = rapp.NewContext(true, header)
+	require.False(t, rapp.accountKeeper.HasCoins(ctx, addr3, sdk.Coins{{"RegistryCoin", 51}}), "Stake unlocked before the ballot is finalized")
+
+	deliverBlock(11)
+
+	ctx = rapp.NewContext(true, header)
+	require.Equal(t, "Unique registry listing", rapp.ballotKeeper.GetListing(ctx, "Unique registry listing").Identifier)
+
+	// Unrevealed commitment is purged and half of its stake is burned
+	require.Equal(t, tcr.Commitment{}, rapp.ballotKeeper.GetCommitment(ctx, addr4, "Unique registry listing"))
+	require.True(t, rapp.accountKeeper.HasCoins(ctx, addr4, sdk.Coins{{"RegistryCoin", 90}}), fmt.Sprintf("Unrevealed voter has: %v", rapp.accountKeeper.GetCoins(ctx, addr4)))
+	require.False(t, rapp.accountKeeper.HasCoins(ctx, addr4, sdk.Coins{{"RegistryCoin", 91}}), fmt.Sprintf("Unrevealed voter has: %v", rapp.accountKeeper.GetCoins(ctx, addr4)))
+
+	// Revealed voter gets the whole stake back with the vote reward once the ballot is finalized
+	// 100 - 50 + 100
+	require.True(t, rapp.accountKeeper.HasCoins(ctx, addr3, sdk.Coins{{"RegistryCoin", 150}}), fmt.Sprintf("Winning voter has: %v", rapp.accountKeeper.GetCoins(ctx, addr3)))
+	require.False(t, rapp.accountKeeper.HasCoins(ctx, addr3, sdk.Coins{{"RegistryCoin", 151}}), fmt.Sprintf("Winning voter has: %v", rapp.accountKeeper.GetCoins(ctx, addr3)))
+
+	// Nothing is left in the store for the ballot but the ballot itself
+	appState, err := rapp.ExportAppStateJSON()
+	require.Nil(t, err)
+	var exported tcr.GenesisState
+	err = rapp.cdc.UnmarshalJSON(appState, &exported)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(exported.Registry.Commitments))
+	require.Equal(t, 0, len(exported.Registry.Votes))
+}
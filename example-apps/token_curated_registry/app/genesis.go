@@ -0,0 +1,39 @@
+package app
+
+import (
+	"encoding/json"
+
+	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// RegistryAppInit is the server's default app init with the genesis account
+// funded in RegistryCoin and the default registry params
+func RegistryAppInit() server.AppInit {
+	appInit := server.DefaultAppInit
+	appInit.AppGenState = RegistryAppGenState
+	return appInit
+}
+
+// RegistryAppGenState creates the genesis accounts of the default app init,
+// holding RegistryCoin instead of the default denomination, and default params
+func RegistryAppGenState(cdc *wire.Codec, appGenTxs []json.RawMessage) (appState json.RawMessage, err error) {
+	simpleState, err := server.SimpleAppGenState(cdc, appGenTxs)
+	if err != nil {
+		return nil, err
+	}
+	var genesisState tcr.GenesisState
+	err = cdc.UnmarshalJSON(simpleState, &genesisState)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, acc := range genesisState.Accounts {
+		for i := range acc.Coins {
+			acc.Coins[i].Denom = tcr.TokenName
+		}
+	}
+	genesisState.Params = tcr.DefaultParams()
+	return wire.MarshalJSONIndent(cdc, genesisState)
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	abci "github.com/tendermint/abci/types"
+)
+
+// Query answers the registry's custom query paths and passes every other path
+// to BaseApp. Custom paths read the check state: the last committed state plus
+// the txs that passed CheckTx since, which are not final until committed.
+func (app *RegistryApp) Query(req abci.RequestQuery) abci.ResponseQuery {
+	if !strings.HasPrefix(req.Path, "/custom/registry/") {
+		return app.BaseApp.Query(req)
+	}
+	ctx := app.NewContext(true, abci.Header{})
+
+	var res interface{}
+	switch req.Path {
+	case tcr.QueryListing:
+		listing := app.ballotKeeper.GetListing(ctx, string(req.Data))
+		if reflect.DeepEqual(listing, tcr.Listing{}) {
+			return tcr.ErrInvalidBallot(tcr.DefaultCodespace, "Listing with given identifier does not exist").QueryResult()
+		}
+		res = listing
+	case tcr.QueryListings:
+		params := tcr.ListingsParams{Limit: tcr.DefaultListingsLimit}
+		if len(req.Data) != 0 {
+			err := json.Unmarshal(req.Data, &params)
+			if err != nil {
+				return sdk.ErrUnknownRequest("Invalid listings params: " + err.Error()).QueryResult()
+			}
+		}
+		if params.Page < 0 || params.Limit <= 0 || params.Limit > tcr.MaxListingsLimit {
+			return sdk.ErrUnknownRequest(fmt.Sprintf("Page must not be negative and limit must be between 1 and %d", tcr.MaxListingsLimit)).QueryResult()
+		}
+		res = app.ballotKeeper.GetListings(ctx, params.Page*params.Limit, params.Limit)
+	case tcr.QueryBallot:
+		ballot := app.ballotKeeper.GetBallot(ctx, string(req.Data))
+		if reflect.DeepEqual(ballot, tcr.Ballot{}) {
+			return tcr.ErrInvalidBallot(tcr.DefaultCodespace, "Candidate with given identifier does not exist").QueryResult()
+		}
+		res = tcr.BallotStatus{
+			Ballot: ballot,
+			Phase:  ballot.Phase(app.LastBlockHeight()),
+			Height: app.LastBlockHeight(),
+		}
+	case tcr.QueryQueue:
+		res = app.ballotKeeper.ProposalQueueItems(ctx)
+	default:
+		return sdk.ErrUnknownRequest("Unknown registry query path").QueryResult()
+	}
+
+	bz, err := wire.MarshalJSONIndent(app.cdc, res)
+	if err != nil {
+		return sdk.ErrInternal(err.Error()).QueryResult()
+	}
+	return abci.ResponseQuery{Value: bz}
+}
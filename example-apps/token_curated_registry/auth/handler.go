@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"bytes"
 	"crypto/sha256"
 	db "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/db"
 	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
@@ -10,10 +11,13 @@ import (
 	"reflect"
 )
 
-func NewCandidacyHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper, minBond int64, applyLen int64) sdk.Handler {
+func NewCandidacyHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper, paramsKeeper db.ParamsKeeper) sdk.Handler {
 	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		declareMsg := msg.(tcr.DeclareCandidacyMsg)
-		if declareMsg.Identifier == "" || declareMsg.Identifier == "candidateQueue" {
+		params := paramsKeeper.GetParams(ctx)
+		minBond := params.MinDeposit
+		applyLen := params.ApplyStage
+		if tcr.IsReservedIdentifier(declareMsg.Identifier) {
 			return tcr.ErrInvalidBallot(tcr.DefaultCodespace, "Cannot use reserved identifiers for ballot").Result()
 		}
 		if declareMsg.Deposit.Amount < minBond {
@@ -35,6 +39,7 @@ func NewCandidacyHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper
 			ballot.Active = false
 			ballot.EndCommitBlockStamp = 0
 			ballot.EndApplyBlockStamp = ctx.BlockHeight() + applyLen
+			ballotKeeper.SetBallot(ctx, ballot)
 			// Must push again because old ballot was popped off
 			ballotKeeper.ProposalQueuePush(ctx, declareMsg.Identifier, ballot.EndApplyBlockStamp)
 			return sdk.Result{}
@@ -53,9 +58,13 @@ func NewCandidacyHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper
 	}
 }
 
-func NewChallengeHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper, commitLen int64, revealLen int64, minBond int64) sdk.Handler {
+func NewChallengeHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper, paramsKeeper db.ParamsKeeper) sdk.Handler {
 	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		challengeMsg := msg.(tcr.ChallengeMsg)
+		params := paramsKeeper.GetParams(ctx)
+		commitLen := params.CommitStage
+		revealLen := params.RevealStage
+		minBond := params.MinDeposit
 		_, _, err := accountKeeper.SubtractCoins(ctx, challengeMsg.Owner, []sdk.Coin{challengeMsg.Bond})
 		if err != nil {
 			return err.Result()
@@ -87,6 +96,11 @@ func NewChallengeHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper
 			return err3.Result()
 		}
 
+		// A ballot under the minimum bond was removed instead
+		if reflect.DeepEqual(ballotKeeper.GetBallot(ctx, challengeMsg.Identifier), tcr.Ballot{}) {
+			return sdk.Result{}
+		}
+
 		if ballotKeeper.ProposalQueueContains(ctx, challengeMsg.Identifier) {
 			ballotKeeper.ProposalQueueUpdate(ctx, challengeMsg.Identifier, ctx.BlockHeight() + commitLen + revealLen)
 		} else {
@@ -97,7 +111,7 @@ func NewChallengeHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper
 	}
 }
 
-func NewCommitHandler(cdc *amino.Codec, ballotKeeper db.BallotKeeper) sdk.Handler {
+func NewCommitHandler(cdc *amino.Codec, accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper, paramsKeeper db.ParamsKeeper) sdk.Handler {
 	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		commitMsg := msg.(tcr.CommitMsg)
 
@@ -111,18 +125,34 @@ func NewCommitHandler(cdc *amino.Codec, ballotKeeper db.BallotKeeper) sdk.Handle
 			return tcr.ErrInvalidPhase(2, "Candidate not in commit phase").Result()
 		}
 
-		ballotKeeper.CommitBallot(ctx, commitMsg.Owner, commitMsg.Identifier, commitMsg.Commitment)
+		if commitMsg.Bond.Amount < paramsKeeper.GetParams(ctx).MinCommitStake {
+			return tcr.ErrInvalidBond(tcr.DefaultCodespace, "Must lock at least the minimum commit stake").Result()
+		}
+
+		// Changing the commitment only locks or unlocks the difference in stake
+		commitment := ballotKeeper.GetCommitment(ctx, commitMsg.Owner, commitMsg.Identifier)
+		diff := commitMsg.Bond.Amount - commitment.Stake
+		if diff > 0 {
+			_, _, err := accountKeeper.SubtractCoins(ctx, commitMsg.Owner, []sdk.Coin{{tcr.TokenName, diff}})
+			if err != nil {
+				return err.Result()
+			}
+		} else if diff < 0 {
+			_, _, err := accountKeeper.AddCoins(ctx, commitMsg.Owner, []sdk.Coin{{tcr.TokenName, -diff}})
+			if err != nil {
+				return err.Result()
+			}
+		}
+		commitment = tcr.NewCommitment(commitMsg.Commitment, commitMsg.Bond.Amount)
+
+		ballotKeeper.CommitBallot(ctx, commitMsg.Owner, commitMsg.Identifier, commitment)
 		return sdk.Result{}
 	}
 }
 
-func NewRevealHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper) sdk.Handler {
+func NewRevealHandler(ballotKeeper db.BallotKeeper) sdk.Handler {
 	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		revealMsg := msg.(tcr.RevealMsg)
-		_, _, err := accountKeeper.SubtractCoins(ctx, revealMsg.Owner, []sdk.Coin{revealMsg.Bond})
-		if err != nil {
-			return err.Result()
-		}
 
 		candidate := ballotKeeper.GetBallot(ctx, revealMsg.Identifier)
 
@@ -130,7 +160,8 @@ func NewRevealHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper) s
 			return tcr.ErrInvalidBallot(2, "Candidate with given identifier does not exist").Result()
 		}
 
-		if candidate.EndCommitBlockStamp > ctx.BlockHeight() || candidate.EndApplyBlockStamp < ctx.BlockHeight() {
+		// Reveals start the block after the last commit, as in Ballot.Phase
+		if candidate.EndCommitBlockStamp >= ctx.BlockHeight() || candidate.EndApplyBlockStamp < ctx.BlockHeight() {
 			return tcr.ErrInvalidPhase(2, "Candidate not in reveal phase").Result()
 		}
 
@@ -145,14 +176,111 @@ func NewRevealHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper) s
 		hasher.Sum(vz)
 		val := hasher.Sum(revealMsg.Nonce)
 
-		if !reflect.DeepEqual(val, commitment) {
+		if !reflect.DeepEqual(val, commitment.Hash) {
 			return tcr.ErrInvalidVote(2, "Vote does not match commitment").Result()
 		}
 
-		ballotKeeper.VoteBallot(ctx, revealMsg.Owner, revealMsg.Identifier, revealMsg.Vote, revealMsg.Bond.Amount)
+		// The stake stays locked as voting power until the ballot is finalized
+		ballotKeeper.VoteBallot(ctx, revealMsg.Owner, revealMsg.Identifier, revealMsg.Vote, commitment.Stake)
 
 		ballotKeeper.DeleteCommitment(ctx, revealMsg.Owner, revealMsg.Identifier)
 
 		return sdk.Result{}
 	}
 }
+
+func NewExitListingHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		exitMsg := msg.(tcr.ExitListingMsg)
+
+		if reflect.DeepEqual(ballotKeeper.GetListing(ctx, exitMsg.Identifier), tcr.Listing{}) {
+			return tcr.ErrInvalidBallot(tcr.DefaultCodespace, "Listing with given identifier does not exist").Result()
+		}
+
+		ballot := ballotKeeper.GetBallot(ctx, exitMsg.Identifier)
+		if !bytes.Equal(ballot.Owner, exitMsg.Owner) {
+			return sdk.ErrUnauthorized("Only the owner can exit a listing").Result()
+		}
+
+		if ballot.Active {
+			return tcr.ErrInvalidPhase(tcr.DefaultCodespace, "Cannot exit a listing while it is challenged").Result()
+		}
+
+		ballotKeeper.DeleteListing(ctx, exitMsg.Identifier)
+		ballotKeeper.DeleteBallot(ctx, exitMsg.Identifier)
+		ballotKeeper.PurgeCommitments(ctx, accountKeeper, exitMsg.Identifier, 0)
+		ballotKeeper.ProposalQueueRemove(ctx, exitMsg.Identifier)
+
+		// Refund the deposit held since candidacy
+		_, _, err := accountKeeper.AddCoins(ctx, exitMsg.Owner, []sdk.Coin{{tcr.TokenName, ballot.Bond}})
+		if err != nil {
+			return err.Result()
+		}
+
+		return sdk.Result{}
+	}
+}
+
+func NewDepositHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		depositMsg := msg.(tcr.DepositMsg)
+
+		if reflect.DeepEqual(ballotKeeper.GetListing(ctx, depositMsg.Identifier), tcr.Listing{}) {
+			return tcr.ErrInvalidBallot(tcr.DefaultCodespace, "Listing with given identifier does not exist").Result()
+		}
+
+		ballot := ballotKeeper.GetBallot(ctx, depositMsg.Identifier)
+
+		if !bytes.Equal(ballot.Owner, depositMsg.Owner) {
+			return sdk.ErrUnauthorized("Only the owner can add to the deposit").Result()
+		}
+
+		if ballot.Active {
+			return tcr.ErrInvalidPhase(tcr.DefaultCodespace, "Cannot change the deposit while challenged").Result()
+		}
+
+		_, _, err := accountKeeper.SubtractCoins(ctx, depositMsg.Owner, []sdk.Coin{depositMsg.Amount})
+		if err != nil {
+			return err.Result()
+		}
+
+		ballotKeeper.SetBond(ctx, depositMsg.Identifier, ballot.Bond + depositMsg.Amount.Amount)
+
+		return sdk.Result{}
+	}
+}
+
+func NewWithdrawHandler(accountKeeper bank.Keeper, ballotKeeper db.BallotKeeper, paramsKeeper db.ParamsKeeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		withdrawMsg := msg.(tcr.WithdrawMsg)
+		minBond := paramsKeeper.GetParams(ctx).MinDeposit
+
+		if reflect.DeepEqual(ballotKeeper.GetListing(ctx, withdrawMsg.Identifier), tcr.Listing{}) {
+			return tcr.ErrInvalidBallot(tcr.DefaultCodespace, "Listing with given identifier does not exist").Result()
+		}
+
+		ballot := ballotKeeper.GetBallot(ctx, withdrawMsg.Identifier)
+
+		if !bytes.Equal(ballot.Owner, withdrawMsg.Owner) {
+			return sdk.ErrUnauthorized("Only the owner can withdraw from the deposit").Result()
+		}
+
+		if ballot.Active {
+			return tcr.ErrInvalidPhase(tcr.DefaultCodespace, "Cannot change the deposit while challenged").Result()
+		}
+
+		remaining := ballot.Bond - withdrawMsg.Amount.Amount
+		if remaining < minBond {
+			return tcr.ErrInvalidDeposit(tcr.DefaultCodespace, "Can only withdraw the deposit above the minimum deposit").Result()
+		}
+
+		ballotKeeper.SetBond(ctx, withdrawMsg.Identifier, remaining)
+
+		_, _, err := accountKeeper.AddCoins(ctx, withdrawMsg.Owner, []sdk.Coin{withdrawMsg.Amount})
+		if err != nil {
+			return err.Result()
+		}
+
+		return sdk.Result{}
+	}
+}
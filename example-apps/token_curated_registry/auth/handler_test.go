@@ -14,6 +14,20 @@ import (
 	"testing"
 )
 
+var testParams = tcr.Params{
+	MinDeposit:      100,
+	ApplyStage:      20,
+	CommitStage:     10,
+	RevealStage:     10,
+	DispensationPct: 0.5,
+	Quorum:          0.5,
+
+	MaxResolvePerBlock: 100,
+
+	MinCommitStake:       10,
+	UnrevealedPenaltyPct: 0.5,
+}
+
 func TestCandidacyHandler(t *testing.T) {
 	// setup
 	addr := utils.GenerateAddress()
@@ -21,18 +35,20 @@ func TestCandidacyHandler(t *testing.T) {
 		Denom:  "RegistryCoin",
 		Amount: 100,
 	})
-	ms, listKey, ballotKey, accountKey := db.SetupMultiStore()
+	ms, listKey, ballotKey, accountKey, paramsKey := db.SetupMultiStore()
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
 
 	cdc := db.MakeCodec()
 
 	keeper := db.NewBallotKeeper(listKey, ballotKey, cdc)
+	paramsKeeper := db.NewParamsKeeper(paramsKey, cdc)
+	paramsKeeper.SetParams(ctx, testParams)
 
 	accountMapper := auth.NewAccountMapper(cdc, accountKey, &auth.BaseAccount{})
 	accountKeeper := bank.NewKeeper(accountMapper)
 
 	// set handler
-	handler := NewCandidacyHandler(accountKeeper, keeper, 100, 10)
+	handler := NewCandidacyHandler(accountKeeper, keeper, paramsKeeper)
 
 	res := handler(ctx, msg)
 
@@ -63,9 +79,17 @@ func TestCandidacyHandler(t *testing.T) {
 	res = handler(ctx, msg)
 
 	assert.True(t, keeper.ProposalQueueContains(ctx, "Unique registry listing"), "Proposal queue does not contain listing")
-	assert.Equal(t, int(ctx.BlockHeight() + 10), keeper.ProposalQueueGetPriority(ctx, "Unique registry listing"), "Proposal added with incorrect priority")
+	assert.Equal(t, int(ctx.BlockHeight() + 20), keeper.ProposalQueueGetPriority(ctx, "Unique registry listing"), "Proposal added with incorrect priority")
 
 	assert.Equal(t, sdk.ABCICodeType(0x1000a), res.Code, "Candidate allowed to be added twice")
+
+	// Identifiers containing the key separator would collide with the commitment or vote keys
+	res = handler(ctx, tcr.NewDeclareCandidacyMsg(addr, "Unique registry listing" + tcr.KeySeparator + "votes", sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 100,
+	}))
+	assert.Equal(t, sdk.ABCICodeType(0x20067), res.Code, "Reserved identifier allowed")
+	assert.False(t, tcr.IsReservedIdentifier("votes-for-cats"), "Plain identifier reserved")
 }
 
 func TestChallengeHandler(t *testing.T) {
@@ -82,18 +106,20 @@ func TestChallengeHandler(t *testing.T) {
 		Denom:  "RegistryCoin",
 		Amount: 100,
 	})
-	ms, listKey, ballotKey, accountKey := db.SetupMultiStore()
+	ms, listKey, ballotKey, accountKey, paramsKey := db.SetupMultiStore()
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
 
 	cdc := db.MakeCodec()
 
 	keeper := db.NewBallotKeeper(listKey, ballotKey, cdc)
+	paramsKeeper := db.NewParamsKeeper(paramsKey, cdc)
+	paramsKeeper.SetParams(ctx, testParams)
 
 	accountMapper := auth.NewAccountMapper(cdc, accountKey, &auth.BaseAccount{})
 	accountKeeper := bank.NewKeeper(accountMapper)
 
 	// set handlers
-	declareHandler := NewCandidacyHandler(accountKeeper, keeper, 100, 10)
+	declareHandler := NewCandidacyHandler(accountKeeper, keeper, paramsKeeper)
 
 	// fund account
 	account := auth.NewBaseAccountWithAddress(addr)
@@ -105,7 +131,7 @@ func TestChallengeHandler(t *testing.T) {
 
 	declareHandler(ctx, msg)
 
-	handler := NewChallengeHandler(accountKeeper, keeper, 10, 10, 100)
+	handler := NewChallengeHandler(accountKeeper, keeper, paramsKeeper)
 
 	res := handler(ctx, challengeMsg)
 
@@ -119,7 +145,7 @@ func TestChallengeHandler(t *testing.T) {
 	}})
 	accountMapper.SetAccount(ctx, &challengerAcc)
 
-	assert.Equal(t, int(ctx.BlockHeight() + 10), keeper.ProposalQueueGetPriority(ctx, "Unique registry listing"), "Priority in queue before challenge is wrong")
+	assert.Equal(t, int(ctx.BlockHeight() + 20), keeper.ProposalQueueGetPriority(ctx, "Unique registry listing"), "Priority in queue before challenge is wrong")
 
 	res = handler(ctx, challengeMsg)
 
@@ -153,20 +179,22 @@ func TestCommitHandler(t *testing.T) {
 		Denom:  "RegistryCoin",
 		Amount: 100,
 	})
-	ms, listKey, ballotKey, accountKey := db.SetupMultiStore()
+	ms, listKey, ballotKey, accountKey, paramsKey := db.SetupMultiStore()
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
 
 	cdc := db.MakeCodec()
 
 	keeper := db.NewBallotKeeper(listKey, ballotKey, cdc)
+	paramsKeeper := db.NewParamsKeeper(paramsKey, cdc)
+	paramsKeeper.SetParams(ctx, testParams)
 
 	accountMapper := auth.NewAccountMapper(cdc, accountKey, &auth.BaseAccount{})
 	accountKeeper := bank.NewKeeper(accountMapper)
 
 	// set handlers
-	declareHandler := NewCandidacyHandler(accountKeeper, keeper, 100, 10)
-	challengeHandler := NewChallengeHandler(accountKeeper, keeper, 10, 10, 100)
-	commitHandler := NewCommitHandler(cdc, keeper)
+	declareHandler := NewCandidacyHandler(accountKeeper, keeper, paramsKeeper)
+	challengeHandler := NewChallengeHandler(accountKeeper, keeper, paramsKeeper)
+	commitHandler := NewCommitHandler(cdc, accountKeeper, keeper, paramsKeeper)
 
 	// fund account
 	account := auth.NewBaseAccountWithAddress(addr)
@@ -186,7 +214,10 @@ func TestCommitHandler(t *testing.T) {
 	}})
 	accountMapper.SetAccount(ctx, &challengerAcc)
 
-	commitMsg := tcr.NewCommitMsg(committer, "Unique registry listing", []byte("My commitment"))
+	commitMsg := tcr.NewCommitMsg(committer, "Unique registry listing", []byte("My commitment"), sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 10,
+	})
 
 	// Check that you cannot commit before challenge
 	res := commitHandler(ctx, commitMsg)
@@ -194,14 +225,44 @@ func TestCommitHandler(t *testing.T) {
 
 	challengeHandler(ctx, challengeMsg)
 
+	// Check that you cannot commit without the commit stake
+	res = commitHandler(ctx, commitMsg)
+	assert.Equal(t, sdk.ABCICodeType(0x1000a), res.Code, "Allowed commitment without stake")
+
+	// Check that you cannot lock less than the minimum commit stake
+	smallMsg := tcr.NewCommitMsg(committer, "Unique registry listing", []byte("My commitment"), sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 9,
+	})
+	res = commitHandler(ctx, smallMsg)
+	assert.Equal(t, sdk.ABCICodeType(0x20066), res.Code, "Allowed commitment below minimum stake")
+
+	committerAcc := auth.NewBaseAccountWithAddress(committer)
+	committerAcc.SetCoins([]sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 15,
+	}})
+	accountMapper.SetAccount(ctx, &committerAcc)
+
 	res = commitHandler(ctx, commitMsg)
 
 	// Check commit store updated
 	commitment := keeper.GetCommitment(ctx, commitMsg.Owner, commitMsg.Identifier)
-	assert.Equal(t, commitMsg.Commitment, commitment, "Commitment not set correctly")
+	assert.Equal(t, tcr.NewCommitment(commitMsg.Commitment, 10), commitment, "Commitment not set correctly")
 
 	assert.Equal(t, sdk.Result{}, res, "Valid commitment msg did not pass")
 
+	// Changing the commitment only locks the difference in stake
+	commitMsg.Commitment = []byte("My new commitment")
+	commitMsg.Bond.Amount = 15
+	res = commitHandler(ctx, commitMsg)
+	assert.Equal(t, sdk.Result{}, res, "Changed commitment msg did not pass")
+	assert.Equal(t, tcr.NewCommitment(commitMsg.Commitment, 15), keeper.GetCommitment(ctx, commitMsg.Owner, commitMsg.Identifier), "Commitment not changed correctly")
+	assert.False(t, accountKeeper.HasCoins(ctx, committer, []sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 1,
+	}}), "Stake difference not locked")
+
 }
 
 func TestRevealHandler(t *testing.T) {
@@ -219,21 +280,23 @@ func TestRevealHandler(t *testing.T) {
 		Denom:  "RegistryCoin",
 		Amount: 100,
 	})
-	ms, listKey, ballotKey, accountKey := db.SetupMultiStore()
+	ms, listKey, ballotKey, accountKey, paramsKey := db.SetupMultiStore()
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
 
 	cdc := db.MakeCodec()
 
 	keeper := db.NewBallotKeeper(listKey, ballotKey, cdc)
+	paramsKeeper := db.NewParamsKeeper(paramsKey, cdc)
+	paramsKeeper.SetParams(ctx, testParams)
 
 	accountMapper := auth.NewAccountMapper(cdc, accountKey, &auth.BaseAccount{})
 	accountKeeper := bank.NewKeeper(accountMapper)
 
 	// set handlers
-	declareHandler := NewCandidacyHandler(accountKeeper, keeper, 100, 10)
-	challengeHandler := NewChallengeHandler(accountKeeper, keeper, 10, 10, 100)
-	commitHandler := NewCommitHandler(cdc, keeper)
-	revealHandler := NewRevealHandler(accountKeeper, keeper)
+	declareHandler := NewCandidacyHandler(accountKeeper, keeper, paramsKeeper)
+	challengeHandler := NewChallengeHandler(accountKeeper, keeper, paramsKeeper)
+	commitHandler := NewCommitHandler(cdc, accountKeeper, keeper, paramsKeeper)
+	revealHandler := NewRevealHandler(keeper)
 
 	// fund account
 	account := auth.NewBaseAccountWithAddress(addr)
@@ -269,23 +332,24 @@ func TestRevealHandler(t *testing.T) {
 	commitment := hasher.Sum([]byte("My secret nonce"))
 
 	// Make commitment
-	commitMsg := tcr.NewCommitMsg(voter, "Unique registry listing", commitment)
-	commitHandler(ctx, commitMsg)
-
-	// Create reveal msg's
-	revealMsg := tcr.NewRevealMsg(voter, "Unique registry listing", true, []byte("My secret nonce"), sdk.Coin{
-		Denom:  "RegistryCoin",
-		Amount: 100,
-	})
-	fakeMsg := tcr.NewRevealMsg(voter, "Unique registry listing", false, []byte("I want to change my vote"), sdk.Coin{
+	commitMsg := tcr.NewCommitMsg(voter, "Unique registry listing", commitment, sdk.Coin{
 		Denom:  "RegistryCoin",
 		Amount: 100,
 	})
+	commitHandler(ctx, commitMsg)
+
+	// Create reveal msg's
+	revealMsg := tcr.NewRevealMsg(voter, "Unique registry listing", true, []byte("My secret nonce"))
+	fakeMsg := tcr.NewRevealMsg(voter, "Unique registry listing", false, []byte("I want to change my vote"))
 
 	// Revealing before reveal phase fails
 	res := revealHandler(ctx, revealMsg)
 	assert.Equal(t, sdk.ABCICodeType(0x20068), res.Code, "Allowed reveal msg to pass before reveal phase")
 
+	// Including the last block of the commit phase
+	res = revealHandler(ctx.WithBlockHeight(10), revealMsg)
+	assert.Equal(t, sdk.ABCICodeType(0x20068), res.Code, "Allowed reveal msg to pass in the last block of the commit phase")
+
 	// Fast forward block height
 	ctx = ctx.WithBlockHeight(11)
 
@@ -309,6 +373,17 @@ func TestRevealHandler(t *testing.T) {
 	assert.Equal(t, int64(0), ballot.Deny, "Deny votes is incorrect")
 	assert.Equal(t, expectedVote, savedVote, "Vote not saved in ballotStore correctly")
 	assert.Equal(t, sdk.Result{}, res, "Reveal handling did not pass")
+	assert.Equal(t, tcr.Commitment{}, keeper.GetCommitment(ctx, revealMsg.Owner, "Unique registry listing"), "Revealed commitment not deleted")
+
+	// Voting stake stays locked after reveal
+	assert.True(t, accountKeeper.HasCoins(ctx, voter, []sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 300,
+	}}), "Voting stake not locked at commit")
+	assert.False(t, accountKeeper.HasCoins(ctx, voter, []sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 301,
+	}}), "Voting stake unlocked at reveal")
 
 	// Check that revealing (voting) twice fails
 	res = revealHandler(ctx, revealMsg)
@@ -317,3 +392,162 @@ func TestRevealHandler(t *testing.T) {
 	assert.Equal(t, int64(100), ballot.Approve, "Allowed user to vote twice")
 	assert.Equal(t, sdk.ABCICodeType(0x20069), res.Code, "Handler did not fail as expected when voting twice")
 }
+
+func TestExitListingHandler(t *testing.T) {
+	// setup
+	addr := utils.GenerateAddress()
+	other := utils.GenerateAddress()
+
+	msg := tcr.NewDeclareCandidacyMsg(addr, "Unique registry listing", sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 100,
+	})
+	ms, listKey, ballotKey, accountKey, paramsKey := db.SetupMultiStore()
+	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
+
+	cdc := db.MakeCodec()
+
+	keeper := db.NewBallotKeeper(listKey, ballotKey, cdc)
+	paramsKeeper := db.NewParamsKeeper(paramsKey, cdc)
+	paramsKeeper.SetParams(ctx, testParams)
+
+	accountMapper := auth.NewAccountMapper(cdc, accountKey, &auth.BaseAccount{})
+	accountKeeper := bank.NewKeeper(accountMapper)
+
+	// fund account
+	account := auth.NewBaseAccountWithAddress(addr)
+	account.SetCoins([]sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 150,
+	}})
+	accountMapper.SetAccount(ctx, &account)
+
+	handler := NewExitListingHandler(accountKeeper, keeper)
+
+	// Cannot exit a listing that does not exist
+	res := handler(ctx, tcr.NewExitListingMsg(addr, "Unique registry listing"))
+	assert.Equal(t, sdk.ABCICodeType(0x20067), res.Code, "Allowed exit of nonexistent listing")
+
+	declareHandler := NewCandidacyHandler(accountKeeper, keeper, paramsKeeper)
+	declareHandler(ctx, msg)
+
+	// Candidate cannot exit before it is listed
+	res = handler(ctx, tcr.NewExitListingMsg(addr, "Unique registry listing"))
+	assert.Equal(t, sdk.ABCICodeType(0x20067), res.Code, "Allowed exit of unlisted candidate")
+
+	keeper.ProposalQueueRemove(ctx, "Unique registry listing")
+	keeper.AddListing(ctx, "Unique registry listing", 0)
+	keeper.CommitBallot(ctx, other, "Unique registry listing", tcr.NewCommitment([]byte("Unrevealed commitment"), 0))
+
+	// Only the owner can exit
+	res = handler(ctx, tcr.NewExitListingMsg(other, "Unique registry listing"))
+	assert.Equal(t, sdk.ABCICodeType(0x10004), res.Code, "Allowed exit by non-owner")
+
+	// Cannot exit while challenged
+	keeper.ActivateBallot(ctx, accountKeeper, addr, other, "Unique registry listing", 10, 10, 100, 100)
+
+	res = handler(ctx, tcr.NewExitListingMsg(addr, "Unique registry listing"))
+	assert.Equal(t, sdk.ABCICodeType(0x20068), res.Code, "Allowed exit while challenged")
+
+	keeper.DeactivateBallot(ctx, "Unique registry listing")
+
+	// A ballot whose key starts with the commitment prefix of the listing
+	keeper.AddBallot(ctx, "Unique registry listingcommits", other, 20, 100)
+
+	res = handler(ctx, tcr.NewExitListingMsg(addr, "Unique registry listing"))
+	assert.Equal(t, sdk.Result{}, res, "Handler did not pass")
+
+	assert.Equal(t, tcr.Listing{}, keeper.GetListing(ctx, "Unique registry listing"), "Listing not deleted")
+	assert.Equal(t, tcr.Ballot{}, keeper.GetBallot(ctx, "Unique registry listing"), "Ballot not deleted")
+	assert.Equal(t, tcr.Commitment{}, keeper.GetCommitment(ctx, other, "Unique registry listing"), "Commitments not deleted")
+	assert.Equal(t, "Unique registry listingcommits", keeper.GetBallot(ctx, "Unique registry listingcommits").Identifier, "Other ballot deleted with the commitments")
+
+	// Deposit refunded
+	assert.True(t, accountKeeper.HasCoins(ctx, addr, []sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 150,
+	}}), "Deposit not refunded")
+}
+
+func TestDepositWithdrawHandler(t *testing.T) {
+	// setup
+	addr := utils.GenerateAddress()
+	other := utils.GenerateAddress()
+
+	msg := tcr.NewDeclareCandidacyMsg(addr, "Unique registry listing", sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 100,
+	})
+	ms, listKey, ballotKey, accountKey, paramsKey := db.SetupMultiStore()
+	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
+
+	cdc := db.MakeCodec()
+
+	keeper := db.NewBallotKeeper(listKey, ballotKey, cdc)
+	paramsKeeper := db.NewParamsKeeper(paramsKey, cdc)
+	paramsKeeper.SetParams(ctx, testParams)
+
+	accountMapper := auth.NewAccountMapper(cdc, accountKey, &auth.BaseAccount{})
+	accountKeeper := bank.NewKeeper(accountMapper)
+
+	// fund account
+	account := auth.NewBaseAccountWithAddress(addr)
+	account.SetCoins([]sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 200,
+	}})
+	accountMapper.SetAccount(ctx, &account)
+
+	declareHandler := NewCandidacyHandler(accountKeeper, keeper, paramsKeeper)
+	declareHandler(ctx, msg)
+
+	depositHandler := NewDepositHandler(accountKeeper, keeper)
+	withdrawHandler := NewWithdrawHandler(accountKeeper, keeper, paramsKeeper)
+	amount := sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 50,
+	}
+
+	// Cannot change the deposit before the candidate is listed
+	res := depositHandler(ctx, tcr.NewDepositMsg(addr, "Unique registry listing", amount))
+	assert.Equal(t, sdk.ABCICodeType(0x20067), res.Code, "Allowed deposit on unlisted candidate")
+	res = withdrawHandler(ctx, tcr.NewWithdrawMsg(addr, "Unique registry listing", amount))
+	assert.Equal(t, sdk.ABCICodeType(0x20067), res.Code, "Allowed withdrawal from unlisted candidate")
+
+	keeper.ProposalQueueRemove(ctx, "Unique registry listing")
+	keeper.AddListing(ctx, "Unique registry listing", 0)
+
+	// Only the owner can change the deposit
+	res = depositHandler(ctx, tcr.NewDepositMsg(other, "Unique registry listing", amount))
+	assert.Equal(t, sdk.ABCICodeType(0x10004), res.Code, "Allowed deposit by non-owner")
+
+	res = depositHandler(ctx, tcr.NewDepositMsg(addr, "Unique registry listing", amount))
+	assert.Equal(t, sdk.Result{}, res, "Deposit handler did not pass")
+	assert.Equal(t, int64(150), keeper.GetBallot(ctx, "Unique registry listing").Bond, "Bond not increased")
+	assert.True(t, accountKeeper.HasCoins(ctx, addr, []sdk.Coin{amount}), "Account not deducted by deposit")
+
+	// Cannot withdraw below the minimum deposit
+	res = withdrawHandler(ctx, tcr.NewWithdrawMsg(addr, "Unique registry listing", sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 60,
+	}))
+	assert.Equal(t, sdk.ABCICodeType(0x20065), res.Code, "Allowed withdrawal below minimum deposit")
+
+	// Cannot change the deposit while challenged
+	keeper.ActivateBallot(ctx, accountKeeper, addr, other, "Unique registry listing", 10, 10, 100, 150)
+
+	res = withdrawHandler(ctx, tcr.NewWithdrawMsg(addr, "Unique registry listing", amount))
+	assert.Equal(t, sdk.ABCICodeType(0x20068), res.Code, "Allowed withdrawal while challenged")
+	res = depositHandler(ctx, tcr.NewDepositMsg(addr, "Unique registry listing", amount))
+	assert.Equal(t, sdk.ABCICodeType(0x20068), res.Code, "Allowed deposit while challenged")
+
+	keeper.DeactivateBallot(ctx, "Unique registry listing")
+
+	res = withdrawHandler(ctx, tcr.NewWithdrawMsg(addr, "Unique registry listing", amount))
+	assert.Equal(t, sdk.Result{}, res, "Withdraw handler did not pass")
+	assert.Equal(t, int64(100), keeper.GetBallot(ctx, "Unique registry listing").Bond, "Bond not decreased")
+	assert.True(t, accountKeeper.HasCoins(ctx, addr, []sdk.Coin{sdk.Coin{
+		Denom:  "RegistryCoin",
+		Amount: 100,
+	}}), "Withdrawal not refunded")
+}
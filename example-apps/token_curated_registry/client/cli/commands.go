@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagIdentifier = "identifier"
+	flagDetails    = "details"
+	flagDeposit    = "deposit"
+	flagBond       = "bond"
+	flagVote       = "vote"
+	flagNonce      = "nonce"
+	flagAmount     = "amount"
+)
+
+func DeclareCandidacyTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "declare",
+		Short: "Declare candidacy of a new listing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(tcr.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			identifier, err := getIdentifier()
+			if err != nil {
+				return err
+			}
+
+			deposit, err := getCoin(flagDeposit)
+			if err != nil {
+				return err
+			}
+
+			msg := tcr.DeclareCandidacyMsg{
+				Owner:      owner,
+				Identifier: identifier,
+				Details:    viper.GetString(flagDetails),
+				Deposit:    deposit,
+			}
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Candidacy declared for: %s\n", identifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagIdentifier, "", "Unique identifier of the listing")
+	cmd.Flags().String(flagDetails, "", "Details to convince voters to approve the listing")
+	cmd.Flags().String(flagDeposit, "", "Deposit in RegistryCoin, at least the minimum deposit")
+	return cmd
+}
+
+func ChallengeTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "challenge",
+		Short: "Challenge a candidate or a listing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(tcr.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			identifier, err := getIdentifier()
+			if err != nil {
+				return err
+			}
+
+			bond, err := getCoin(flagBond)
+			if err != nil {
+				return err
+			}
+
+			msg := tcr.NewChallengeMsg(owner, identifier, bond)
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Challenged: %s\n", identifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagIdentifier, "", "Identifier of the challenged listing")
+	cmd.Flags().String(flagBond, "", "Bond in RegistryCoin, at least the candidate's deposit")
+	return cmd
+}
+
+func CommitTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Commit to a vote on a challenged listing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(tcr.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			identifier, err := getIdentifier()
+			if err != nil {
+				return err
+			}
+
+			nonce, err := getNonce()
+			if err != nil {
+				return err
+			}
+
+			bond, err := getCoin(flagBond)
+			if err != nil {
+				return err
+			}
+
+			commitment := makeCommitment(cdc, viper.GetBool(flagVote), nonce)
+			msg := tcr.NewCommitMsg(owner, identifier, commitment, bond)
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Commitment made on: %s\n", identifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagIdentifier, "", "Identifier of the challenged listing")
+	cmd.Flags().Bool(flagVote, false, "Vote to approve the listing")
+	cmd.Flags().String(flagNonce, "", "Secret nonce to reveal the vote with")
+	cmd.Flags().String(flagBond, "", "Voting power in RegistryCoin, locked until the ballot is finalized")
+	return cmd
+}
+
+func RevealTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reveal",
+		Short: "Reveal a committed vote on a challenged listing",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(tcr.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			identifier, err := getIdentifier()
+			if err != nil {
+				return err
+			}
+
+			nonce, err := getNonce()
+			if err != nil {
+				return err
+			}
+
+			msg := tcr.NewRevealMsg(owner, identifier, viper.GetBool(flagVote), nonce)
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Vote revealed on: %s\n", identifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagIdentifier, "", "Identifier of the challenged listing")
+	cmd.Flags().Bool(flagVote, false, "Committed vote")
+	cmd.Flags().String(flagNonce, "", "Nonce used in the commitment")
+	return cmd
+}
+
+func ExitListingTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exit",
+		Short: "Remove an unchallenged listing you own and get its deposit back",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(tcr.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			identifier, err := getIdentifier()
+			if err != nil {
+				return err
+			}
+
+			msg := tcr.NewExitListingMsg(owner, identifier)
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Listing exited: %s\n", identifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagIdentifier, "", "Identifier of the listing to exit")
+	return cmd
+}
+
+func DepositTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposit",
+		Short: "Add to the deposit of an unchallenged listing you own",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(tcr.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			identifier, err := getIdentifier()
+			if err != nil {
+				return err
+			}
+
+			amount, err := getCoin(flagAmount)
+			if err != nil {
+				return err
+			}
+
+			msg := tcr.NewDepositMsg(owner, identifier, amount)
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Deposit increased on: %s\n", identifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagIdentifier, "", "Identifier of the listing")
+	cmd.Flags().String(flagAmount, "", "Amount of RegistryCoin to add to the deposit")
+	return cmd
+}
+
+func WithdrawTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "withdraw",
+		Short: "Withdraw deposit above the minimum from an unchallenged listing you own",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCoreContextFromViper().WithDecoder(tcr.GetAccountDecoder(cdc))
+			owner, err := ctx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			identifier, err := getIdentifier()
+			if err != nil {
+				return err
+			}
+
+			amount, err := getCoin(flagAmount)
+			if err != nil {
+				return err
+			}
+
+			msg := tcr.NewWithdrawMsg(owner, identifier, amount)
+			_, err = ctx.EnsureSignBuildBroadcast(ctx.FromAddressName, msg, cdc)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Deposit withdrawn from: %s\n", identifier)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagIdentifier, "", "Identifier of the listing")
+	cmd.Flags().String(flagAmount, "", "Amount of RegistryCoin to withdraw from the deposit")
+	return cmd
+}
+
+func getIdentifier() (string, error) {
+	identifier := viper.GetString(flagIdentifier)
+	if len(identifier) == 0 {
+		return "", fmt.Errorf("specify listing identifier with --identifier")
+	}
+	return identifier, nil
+}
+
+func getCoin(flag string) (sdk.Coin, error) {
+	coinString := viper.GetString(flag)
+	if len(coinString) == 0 {
+		return sdk.Coin{}, fmt.Errorf("specify amount of RegistryCoin with --%s", flag)
+	}
+	return sdk.ParseCoin(coinString)
+}
+
+func getNonce() ([]byte, error) {
+	nonce := viper.GetString(flagNonce)
+	if len(nonce) == 0 {
+		return nil, fmt.Errorf("specify secret nonce with --nonce")
+	}
+	return []byte(nonce), nil
+}
+
+// makeCommitment builds a commitment the same way the reveal handler checks
+// it, so that the vote and nonce can be revealed later
+func makeCommitment(cdc *wire.Codec, vote bool, nonce []byte) []byte {
+	hasher := sha256.New()
+	vz, _ := cdc.MarshalBinary(vote)
+	hasher.Sum(vz)
+	return hasher.Sum(nonce)
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
+	"github.com/cosmos/cosmos-sdk/client/context"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagPage  = "page"
+	flagLimit = "limit"
+)
+
+func GetListingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "listing [identifier]",
+		Short: "Query a listing of the registry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return queryRegistry(tcr.QueryListing, []byte(args[0]))
+		},
+	}
+}
+
+func GetListingsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "listings",
+		Short: "Query a page of the registry's listings, ordered by identifier",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := tcr.ListingsParams{
+				Page:  viper.GetInt(flagPage),
+				Limit: viper.GetInt(flagLimit),
+			}
+			bz, err := json.Marshal(params)
+			if err != nil {
+				return err
+			}
+			return queryRegistry(tcr.QueryListings, bz)
+		},
+	}
+	cmd.Flags().Int(flagPage, 0, "Page of listings, starting at 0")
+	cmd.Flags().Int(flagLimit, tcr.DefaultListingsLimit, "Listings per page")
+	return cmd
+}
+
+func GetBallotCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ballot [identifier]",
+		Short: "Query a ballot with its phase and deadlines",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return queryRegistry(tcr.QueryBallot, []byte(args[0]))
+		},
+	}
+}
+
+func GetQueueCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "queue",
+		Short: "Query the candidate queue in order of resolution",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return queryRegistry(tcr.QueryQueue, nil)
+		},
+	}
+}
+
+// queryRegistry prints the JSON result of a custom registry query
+func queryRegistry(path string, data []byte) error {
+	ctx := context.NewCoreContextFromViper()
+	node, err := ctx.GetNode()
+	if err != nil {
+		return err
+	}
+	result, err := node.ABCIQuery(path, data)
+	if err != nil {
+		return err
+	}
+	resp := result.Response
+	if resp.Code != 0 {
+		return fmt.Errorf("Query failed: (%d) %s", resp.Code, resp.Log)
+	}
+	fmt.Println(string(resp.Value))
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tmlibs/cli"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/client/lcd"
+	"github.com/cosmos/cosmos-sdk/client/rpc"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+
+	tcrcmd "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/client/cli"
+
+	"github.com/cosmos/cosmos-sdk/version"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+
+	"github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/app"
+	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
+)
+
+// rootCmd is the entry point for this binary
+var (
+	rootCmd = &cobra.Command{
+		Use:   "tcrcli",
+		Short: "Token-curated registry light-client",
+	}
+)
+
+func main() {
+	// disable sorting
+	cobra.EnableCommandSorting = false
+
+	// get the codec
+	cdc := app.MakeCodec()
+
+	// add standard rpc, and tx commands
+	rpc.AddCommands(rootCmd)
+	rootCmd.AddCommand(client.LineBreak)
+	tx.AddCommands(rootCmd, cdc)
+	rootCmd.AddCommand(client.LineBreak)
+
+	// add query/post commands (custom to binary)
+	rootCmd.AddCommand(
+		client.GetCommands(
+			authcmd.GetAccountCmd("acc", cdc, tcr.GetAccountDecoder(cdc)),
+			tcrcmd.GetListingCmd(),
+			tcrcmd.GetListingsCmd(),
+			tcrcmd.GetBallotCmd(),
+			tcrcmd.GetQueueCmd(),
+		)...)
+
+	// add proxy, version and key info
+	rootCmd.AddCommand(
+		client.LineBreak,
+		lcd.ServeCommand(cdc),
+		keys.Commands(),
+		client.LineBreak,
+		version.VersionCmd,
+	)
+
+	rootCmd.AddCommand(
+		client.PostCommands(
+			tcrcmd.DeclareCandidacyTxCmd(cdc),
+			tcrcmd.ChallengeTxCmd(cdc),
+			tcrcmd.CommitTxCmd(cdc),
+			tcrcmd.RevealTxCmd(cdc),
+			tcrcmd.ExitListingTxCmd(cdc),
+			tcrcmd.DepositTxCmd(cdc),
+			tcrcmd.WithdrawTxCmd(cdc),
+		)...,
+	)
+
+	// prepare and add flags
+	executor := cli.PrepareMainCmd(rootCmd, "TCR", os.ExpandEnv("$HOME/.tcrcli"))
+	executor.Execute()
+}
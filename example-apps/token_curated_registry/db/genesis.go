@@ -0,0 +1,125 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+
+	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Length of the owner address at the end of commitment and vote keys
+const addrLen = 20
+
+// InitGenesis loads the registry state. It must have been validated.
+func (bk BallotKeeper) InitGenesis(ctx sdk.Context, state tcr.RegistryState) {
+	listingStore := ctx.KVStore(bk.ListingKey)
+	for _, listing := range state.Listings {
+		val, _ := bk.Cdc.MarshalBinary(listing)
+		listingStore.Set([]byte(listing.Identifier), val)
+	}
+
+	ballotStore := ctx.KVStore(bk.BallotKey)
+	for _, ballot := range state.Ballots {
+		val, _ := bk.Cdc.MarshalBinary(ballot)
+		ballotStore.Set([]byte(ballot.Identifier), val)
+	}
+	for _, commitment := range state.Commitments {
+		bk.CommitBallot(ctx, commitment.Owner, commitment.Identifier, tcr.NewCommitment(commitment.Commitment, commitment.Stake))
+	}
+	for _, vote := range state.Votes {
+		val, _ := bk.Cdc.MarshalBinary(vote.Vote)
+		ballotStore.Set(append(tcr.VotePrefix(vote.Identifier), vote.Owner...), val)
+	}
+	for _, item := range state.CandidateQueue {
+		bk.ProposalQueuePush(ctx, item.Identifier, item.EndHeight)
+	}
+}
+
+// ExportGenesis returns the registry state, in store order. Keys it can't
+// make sense of are logged and left out rather than failing the export.
+func (bk BallotKeeper) ExportGenesis(ctx sdk.Context) tcr.RegistryState {
+	state := tcr.RegistryState{
+		Listings:       []tcr.Listing{},
+		Ballots:        []tcr.Ballot{},
+		Commitments:    []tcr.GenesisCommitment{},
+		Votes:          []tcr.GenesisVote{},
+		CandidateQueue: []tcr.QueueItem{},
+	}
+
+	listingIter := ctx.KVStore(bk.ListingKey).Iterator(nil, nil)
+	for ; listingIter.Valid(); listingIter.Next() {
+		listing := tcr.Listing{}
+		err := bk.Cdc.UnmarshalBinary(listingIter.Value(), &listing)
+		if err != nil {
+			skipKey(ctx, "listing", listingIter.Key())
+			continue
+		}
+		state.Listings = append(state.Listings, listing)
+	}
+	listingIter.Close()
+
+	// Ballots are stored under their identifier, commitments and votes under
+	// their CommitmentPrefix and VotePrefix followed by the owner
+	var keys, vals [][]byte
+	ballots := make(map[string]bool)
+	ballotIter := ctx.KVStore(bk.BallotKey).Iterator(nil, nil)
+	for ; ballotIter.Valid(); ballotIter.Next() {
+		key, val := ballotIter.Key(), ballotIter.Value()
+		if bytes.HasPrefix(key, []byte("candidateQueue")) {
+			continue
+		}
+		ballot := tcr.Ballot{}
+		err := bk.Cdc.UnmarshalBinary(val, &ballot)
+		if err == nil && ballot.Identifier == string(key) {
+			state.Ballots = append(state.Ballots, ballot)
+			ballots[ballot.Identifier] = true
+			continue
+		}
+		keys = append(keys, key)
+		vals = append(vals, val)
+	}
+	ballotIter.Close()
+
+	for i, key := range keys {
+		if len(key) <= addrLen {
+			skipKey(ctx, "ballot", key)
+			continue
+		}
+		owner := sdk.Address(key[len(key)-addrLen:])
+		prefix := key[:len(key)-addrLen]
+		sep := bytes.Index(prefix, []byte(tcr.KeySeparator))
+		if sep < 0 || !ballots[string(prefix[:sep])] {
+			skipKey(ctx, "ballot", key)
+			continue
+		}
+		identifier := string(prefix[:sep])
+		switch {
+		case bytes.Equal(prefix, tcr.VotePrefix(identifier)):
+			vote := tcr.Vote{}
+			err := bk.Cdc.UnmarshalBinary(vals[i], &vote)
+			if err != nil {
+				skipKey(ctx, "ballot", key)
+				continue
+			}
+			state.Votes = append(state.Votes, tcr.GenesisVote{Identifier: identifier, Owner: owner, Vote: vote})
+		case bytes.Equal(prefix, tcr.CommitmentPrefix(identifier)):
+			commitment := tcr.Commitment{}
+			err := bk.Cdc.UnmarshalBinary(vals[i], &commitment)
+			if err != nil {
+				skipKey(ctx, "ballot", key)
+				continue
+			}
+			state.Commitments = append(state.Commitments, tcr.GenesisCommitment{Identifier: identifier, Owner: owner, Commitment: commitment.Hash, Stake: commitment.Stake})
+		default:
+			skipKey(ctx, "ballot", key)
+		}
+	}
+
+	state.CandidateQueue = bk.ProposalQueueItems(ctx)
+	return state
+}
+
+func skipKey(ctx sdk.Context, store string, key []byte) {
+	ctx.Logger().Error("Skipping unknown key in export", "store", store, "key", fmt.Sprintf("%X", key))
+}
@@ -5,8 +5,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/bank"
 	"github.com/tendermint/go-amino"
-	"reflect"
-	"container/heap"
+	"encoding/binary"
 )
 
 type BallotKeeper struct {
@@ -57,13 +56,23 @@ func (bk BallotKeeper) AddBallot(ctx sdk.Context, identifier string, owner sdk.A
 	return nil
 }
 
+// SetBallot stores a ballot under its identifier
+func (bk BallotKeeper) SetBallot(ctx sdk.Context, ballot tcr.Ballot) {
+	store := ctx.KVStore(bk.BallotKey)
+	val, _ := bk.Cdc.MarshalBinary(ballot)
+	store.Set([]byte(ballot.Identifier), val)
+}
+
 func (bk BallotKeeper) ActivateBallot(ctx sdk.Context, accountKeeper bank.Keeper, owner sdk.Address, challenger sdk.Address, 
 	identifier string, commitLen int64, revealLen, minBond int64, challengeBond int64) sdk.Error {
 	store := ctx.KVStore(bk.BallotKey)
 	ballot := bk.GetBallot(ctx, identifier)
 
 	if ballot.Bond < minBond {
+		// Remove the candidate or listing altogether
 		bk.DeleteBallot(ctx, identifier)
+		bk.DeleteListing(ctx, identifier)
+		bk.ProposalQueueRemove(ctx, identifier)
 		refund := sdk.Coin{
 			Denom:  "RegistryCoin",
 			Amount: challengeBond,
@@ -74,9 +83,16 @@ func (bk BallotKeeper) ActivateBallot(ctx sdk.Context, accountKeeper bank.Keeper
 		}
 		return nil
 	}
-	if ballot.Bond != challengeBond {
+	if challengeBond < ballot.Bond {
 		return sdk.NewError(2, 115, "Must match candidate's bond")
 	}
+	// The challenge stakes the candidate's bond, a larger bond is refunded the excess
+	if challengeBond > ballot.Bond {
+		_, _, err := accountKeeper.AddCoins(ctx, challenger, []sdk.Coin{{"RegistryCoin", challengeBond - ballot.Bond}})
+		if err != nil {
+			return err
+		}
+	}
 
 	ballot.Active = true
 	ballot.Challenger = challenger
@@ -103,36 +119,80 @@ func (bk BallotKeeper) DeactivateBallot(ctx sdk.Context, identifier string) {
 	store.Set(key, newBallot)
 } 
 
-func (bk BallotKeeper) CommitBallot(ctx sdk.Context, owner sdk.Address, identifier string, commitment []byte) {
-	commitKey := []byte(identifier)
-	commitKey = append(commitKey, []byte("commits")...)
-	commitKey = append(commitKey, owner...)
+// SetBond sets the deposit held on a ballot
+func (bk BallotKeeper) SetBond(ctx sdk.Context, identifier string, bond int64) {
+	store := ctx.KVStore(bk.BallotKey)
+	ballot := bk.GetBallot(ctx, identifier)
+	ballot.Bond = bond
+	newBallot, _ := bk.Cdc.MarshalBinary(ballot)
+	key := []byte(identifier)
+	store.Set(key, newBallot)
+}
+
+func (bk BallotKeeper) CommitBallot(ctx sdk.Context, owner sdk.Address, identifier string, commitment tcr.Commitment) {
+	commitKey := append(tcr.CommitmentPrefix(identifier), owner...)
 
 	ballotStore := ctx.KVStore(bk.BallotKey)
 
-	ballotStore.Set(commitKey, commitment)
+	val, _ := bk.Cdc.MarshalBinary(commitment)
+	ballotStore.Set(commitKey, val)
 }
 
-func (bk BallotKeeper) GetCommitment(ctx sdk.Context, owner sdk.Address, identifier string) []byte {
-	commitKey := []byte(identifier)
-	commitKey = append(commitKey, []byte("commits")...)
-	commitKey = append(commitKey, owner...)
+func (bk BallotKeeper) GetCommitment(ctx sdk.Context, owner sdk.Address, identifier string) tcr.Commitment {
+	commitKey := append(tcr.CommitmentPrefix(identifier), owner...)
 
 	ballotStore := ctx.KVStore(bk.BallotKey)
 
-	return ballotStore.Get(commitKey)
+	bz := ballotStore.Get(commitKey)
+	if bz == nil {
+		return tcr.Commitment{}
+	}
+	commitment := tcr.Commitment{}
+	err := bk.Cdc.UnmarshalBinary(bz, &commitment)
+	if err != nil {
+		panic(err)
+	}
+	return commitment
 }
 
 func (bk BallotKeeper) DeleteCommitment(ctx sdk.Context, owner sdk.Address, identifier string) {
-	commitKey := []byte(identifier)
-	commitKey = append(commitKey, []byte("commits")...)
-	commitKey = append(commitKey, owner...)
+	commitKey := append(tcr.CommitmentPrefix(identifier), owner...)
 
 	ballotStore := ctx.KVStore(bk.BallotKey)
 
 	ballotStore.Delete(commitKey)
 }
 
+// PurgeCommitments deletes every unrevealed commitment on a ballot and refunds its stake
+// to the owner, less penaltyPct of it. The penalty is burned.
+func (bk BallotKeeper) PurgeCommitments(ctx sdk.Context, accountKeeper bank.Keeper, identifier string, penaltyPct float64) {
+	prefix := tcr.CommitmentPrefix(identifier)
+	ballotStore := ctx.KVStore(bk.BallotKey)
+	iter := sdk.KVStorePrefixIterator(ballotStore, prefix)
+	var keys [][]byte
+	var commitments []tcr.Commitment
+	for ; iter.Valid(); iter.Next() {
+		commitment := tcr.Commitment{}
+		err := bk.Cdc.UnmarshalBinary(iter.Value(), &commitment)
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, iter.Key())
+		commitments = append(commitments, commitment)
+	}
+	iter.Close()
+
+	for i, key := range keys {
+		ballotStore.Delete(key)
+
+		refund := commitments[i].Stake - int64(float64(commitments[i].Stake) * penaltyPct)
+		if refund > 0 {
+			owner := sdk.Address(key[len(prefix):])
+			accountKeeper.AddCoins(ctx, owner, sdk.Coins{{tcr.TokenName, refund}})
+		}
+	}
+}
+
 func (bk BallotKeeper) VoteBallot(ctx sdk.Context, owner sdk.Address, identifier string, vote bool, power int64) sdk.Error {
 	ballotStore := ctx.KVStore(bk.BallotKey)
 
@@ -161,20 +221,14 @@ func (bk BallotKeeper) VoteBallot(ctx sdk.Context, owner sdk.Address, identifier
 		panic(err2)
 	}
 
-	// Set voteKey to "{identifier}|votes|{owner_address}|
-	voteKey := []byte(identifier)
-	voteKey = append(voteKey, []byte("votes")...)
-	voteKey = append(voteKey, owner...)
+	voteKey := append(tcr.VotePrefix(identifier), owner...)
 
 	ballotStore.Set(voteKey, voteVal)
 	return nil
 }
 
 func (bk BallotKeeper) GetVote(ctx sdk.Context, owner sdk.Address, identifier string) tcr.Vote {
-	// Set voteKey to "{identifier}|votes|{owner_address}|
-	voteKey := []byte(identifier)
-	voteKey = append(voteKey, []byte("votes")...)
-	voteKey = append(voteKey, owner...)
+	voteKey := append(tcr.VotePrefix(identifier), owner...)
 
 	vote := &tcr.Vote{}
 	ballotStore := ctx.KVStore(bk.BallotKey)
@@ -192,9 +246,7 @@ func (bk BallotKeeper) GetVote(ctx sdk.Context, owner sdk.Address, identifier st
 }
 
 func (bk BallotKeeper) DeleteVote(ctx sdk.Context, owner sdk.Address, identifier string) {
-	voteKey := []byte(identifier)
-	voteKey = append(voteKey, []byte("votes")...)
-	voteKey = append(voteKey, owner...)
+	voteKey := append(tcr.VotePrefix(identifier), owner...)
 
 	ballotStore := ctx.KVStore(bk.BallotKey)
 
@@ -244,103 +296,150 @@ func (bk BallotKeeper) DeleteListing(ctx sdk.Context, identifier string) {
 	store.Delete(key)
 }
 
+// GetListings returns up to limit listings ordered by identifier, skipping the first start listings
+func (bk BallotKeeper) GetListings(ctx sdk.Context, start int, limit int) []tcr.Listing {
+	store := ctx.KVStore(bk.ListingKey)
+	iter := store.Iterator(nil, nil)
+	defer iter.Close()
+
+	listings := []tcr.Listing{}
+	for i := 0; iter.Valid() && len(listings) < limit; iter.Next() {
+		if i < start {
+			i++
+			continue
+		}
+		listing := tcr.Listing{}
+		err := bk.Cdc.UnmarshalBinary(iter.Value(), &listing)
+		if err != nil {
+			panic(err)
+		}
+		listings = append(listings, listing)
+	}
+	return listings
+}
+
 // --------------------------------------------------------------------------------------------------
-// Queue stored in key: candidateQueue
+// Queue stored in the ballot store under candidateQueue:{end height}{identifier}, so that
+// iteration returns candidates by end height and then identifier. The end height of every
+// queued candidate is indexed under candidateQueueIndex:{identifier}.
 
-// getCandidateQueue gets the CandidateQueue from the context
-func (bk BallotKeeper) getCandidateQueue(ctx sdk.Context) tcr.PriorityQueue {
-	store := ctx.KVStore(bk.BallotKey)
-	bpq := store.Get([]byte("candidateQueue"))
-	if bpq == nil {
-		return tcr.PriorityQueue{}
-	}
+func queueKey(blockNum int64, identifier string) []byte {
+	return append(queueHeightKey(blockNum), []byte(identifier)...)
+}
 
-	candidateQueue := tcr.PriorityQueue{}
-	err := bk.Cdc.UnmarshalBinaryBare(bpq, &candidateQueue)
-	if err != nil {
-		panic(err)
-	}
+func queueHeightKey(blockNum int64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(blockNum))
+	return append([]byte(tcr.QueuePrefix), bz...)
+}
 
-	return candidateQueue
+func queueIndexKey(identifier string) []byte {
+	return []byte(tcr.QueueIndexPrefix + identifier)
 }
 
-// setProposalQueue sets the CandidateQueue to the context
-func (bk BallotKeeper) setCandidateQueue(ctx sdk.Context, candidateQueue tcr.PriorityQueue) {
+// queueHeadKey returns the key of the first candidate in the queue, nil if it is empty
+func (bk BallotKeeper) queueHeadKey(ctx sdk.Context) []byte {
 	store := ctx.KVStore(bk.BallotKey)
-	bpq, err := bk.Cdc.MarshalBinaryBare(candidateQueue)
-	if err != nil {
-		panic(err)
+	iter := sdk.KVStorePrefixIterator(store, []byte(tcr.QueuePrefix))
+	defer iter.Close()
+	if !iter.Valid() {
+		return nil
 	}
-	store.Set([]byte("candidateQueue"), bpq)
+	return iter.Key()
 }
 
-// ProposalQueueHead returns the head of the PriorityQueue
+// ProposalQueueHead returns the ballot of the candidate with the lowest end height
 func (bk BallotKeeper) ProposalQueueHead(ctx sdk.Context) tcr.Ballot {
-	candidateQueue := bk.getCandidateQueue(ctx)
-	if reflect.DeepEqual(candidateQueue, tcr.PriorityQueue{}) {
+	key := bk.queueHeadKey(ctx)
+	if key == nil {
 		return tcr.Ballot{}
 	}
-	if candidateQueue.Len() == 0 {
-		return tcr.Ballot{}
-	}
-	ballot := bk.GetBallot(ctx, candidateQueue.Peek().Value)
-	return ballot
+	return bk.GetBallot(ctx, string(key[len(tcr.QueuePrefix)+8:]))
 }
 
-// ProposalQueuePop pops the head from the Proposal queue
+// ProposalQueuePop removes the head of the queue and returns its ballot
 func (bk BallotKeeper) ProposalQueuePop(ctx sdk.Context) tcr.Ballot {
-	candidateQueue := bk.getCandidateQueue(ctx)
-	if reflect.DeepEqual(candidateQueue, tcr.PriorityQueue{}) {
+	key := bk.queueHeadKey(ctx)
+	if key == nil {
 		return tcr.Ballot{}
 	}
-	if candidateQueue.Len() == 0 {
-		return tcr.Ballot{}
-	}
-
-	ballot := bk.GetBallot(ctx, heap.Pop(&candidateQueue).(*tcr.Item).Value)
-	bk.setCandidateQueue(ctx, candidateQueue)
-	return ballot
+	identifier := string(key[len(tcr.QueuePrefix)+8:])
+	store := ctx.KVStore(bk.BallotKey)
+	store.Delete(key)
+	store.Delete(queueIndexKey(identifier))
+	return bk.GetBallot(ctx, identifier)
 }
 
-// ProposalQueuePush pushes a candidate to Priority Queue
+// ProposalQueuePush queues a candidate to be resolved at blockNum. A candidate already in the queue is moved.
 func (bk BallotKeeper) ProposalQueuePush(ctx sdk.Context, identifier string, blockNum int64) {
-	candidateQueue := bk.getCandidateQueue(ctx)
+	bk.ProposalQueueRemove(ctx, identifier)
 
-	item := tcr.Item{Value: identifier, Priority: int(blockNum)}
-	heap.Push(&candidateQueue, &item)
-	bk.setCandidateQueue(ctx, candidateQueue)
+	store := ctx.KVStore(bk.BallotKey)
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(blockNum))
+	store.Set(queueKey(blockNum, identifier), []byte{})
+	store.Set(queueIndexKey(identifier), bz)
 }
 
 // ProposalQueueUpdate updates a candidate with new priority
 func (bk BallotKeeper) ProposalQueueUpdate(ctx sdk.Context, identifier string, newBlockNum int64) sdk.Error {
-	candidateQueue := bk.getCandidateQueue(ctx)
-
-	err := candidateQueue.Update(identifier, int(newBlockNum))
-	if err != nil {
-		return err
+	if !bk.ProposalQueueContains(ctx, identifier) {
+		return tcr.ErrInvalidBallot(tcr.DefaultCodespace, "Given identifier not found in queue")
 	}
-	bk.setCandidateQueue(ctx, candidateQueue)
+	bk.ProposalQueuePush(ctx, identifier, newBlockNum)
 	return nil
 }
 
+// ProposalQueueRemove removes a candidate from the queue if it is in it
+func (bk BallotKeeper) ProposalQueueRemove(ctx sdk.Context, identifier string) {
+	blockNum := bk.ProposalQueueGetPriority(ctx, identifier)
+	if blockNum == -1 {
+		return
+	}
+	store := ctx.KVStore(bk.BallotKey)
+	store.Delete(queueKey(int64(blockNum), identifier))
+	store.Delete(queueIndexKey(identifier))
+}
+
 func (bk BallotKeeper) ProposalQueueContains(ctx sdk.Context, identifier string) bool {
-	candidateQueue := bk.getCandidateQueue(ctx)
+	store := ctx.KVStore(bk.BallotKey)
+	return store.Has(queueIndexKey(identifier))
+}
 
-	for _, item := range candidateQueue {
-		if item.Value == identifier {
-			return true
-		}
+// ProposalQueueGetPriority returns the end height of a queued candidate, -1 if it is not queued
+func (bk BallotKeeper) ProposalQueueGetPriority(ctx sdk.Context, identifier string) int {
+	store := ctx.KVStore(bk.BallotKey)
+	bz := store.Get(queueIndexKey(identifier))
+	if bz == nil {
+		return -1
 	}
-	return false
+	return int(binary.BigEndian.Uint64(bz))
 }
 
-func (bk BallotKeeper) ProposalQueueGetPriority(ctx sdk.Context, identifier string) int {
-	candidateQueue := bk.getCandidateQueue(ctx)
+// ProposalQueueItems returns the candidates in the queue in the order they will be popped
+func (bk BallotKeeper) ProposalQueueItems(ctx sdk.Context) []tcr.QueueItem {
+	return bk.ProposalQueueMatured(ctx, -1)
+}
 
-	for _, item := range candidateQueue {
-		if item.Value == identifier {
-			return item.Priority
-		}
+// ProposalQueueMatured returns the queued candidates with an end height up to blockNum,
+// in the order they will be popped. A negative blockNum returns the whole queue.
+func (bk BallotKeeper) ProposalQueueMatured(ctx sdk.Context, blockNum int64) []tcr.QueueItem {
+	store := ctx.KVStore(bk.BallotKey)
+	var iter sdk.Iterator
+	if blockNum >= 0 {
+		iter = store.Iterator([]byte(tcr.QueuePrefix), queueHeightKey(blockNum+1))
+	} else {
+		iter = sdk.KVStorePrefixIterator(store, []byte(tcr.QueuePrefix))
 	}
-	return -1
-}
\ No newline at end of file
+	defer iter.Close()
+
+	items := []tcr.QueueItem{}
+	for ; iter.Valid(); iter.Next() {
+		key := iter.Key()[len(tcr.QueuePrefix):]
+		items = append(items, tcr.QueueItem{
+			Identifier: string(key[8:]),
+			EndHeight:  int64(binary.BigEndian.Uint64(key[:8])),
+		})
+	}
+	return items
+}
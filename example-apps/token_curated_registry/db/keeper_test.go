@@ -8,7 +8,6 @@ import (
 	"github.com/stretchr/testify/assert"
 	abci "github.com/tendermint/abci/types"
 	"testing"
-	"container/heap"
 
 	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
 	"github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/utils"
@@ -16,7 +15,7 @@ import (
 )
 
 func TestAddGet(t *testing.T) {
-	ms, listKey, ballotKey, _ := SetupMultiStore()
+	ms, listKey, ballotKey, _, _ := SetupMultiStore()
 	cdc := MakeCodec()
 
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
@@ -39,7 +38,7 @@ func TestAddGet(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
-	ms, listKey, ballotKey, _ := SetupMultiStore()
+	ms, listKey, ballotKey, _, _ := SetupMultiStore()
 	cdc := MakeCodec()
 
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
@@ -57,7 +56,7 @@ func TestDelete(t *testing.T) {
 }
 
 func TestActivate(t *testing.T) {
-	ms, listKey, ballotKey, accountKey := SetupMultiStore()
+	ms, listKey, ballotKey, accountKey, _ := SetupMultiStore()
 	cdc := MakeCodec()
 
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
@@ -77,12 +76,16 @@ func TestActivate(t *testing.T) {
 	assert.Equal(t, &account, testaccount, "Accounts don't match")
 
 	// Touch and remove case: Bond posted is less than new minBond
+	keeper.AddListing(ctx, "Unique registry listing", 0)
+	keeper.ProposalQueuePush(ctx, "Unique registry listing", 5)
 	challenger := utils.GenerateAddress()
 	keeper.ActivateBallot(ctx, accountKeeper, addr, challenger, "Unique registry listing", 10, 10, 100, 100)
 
 	delBallot := keeper.GetBallot(ctx, "Unique registry listing")
 
 	assert.Equal(t, tcr.Ballot{}, delBallot, "Outdated ballot was not deleted")
+	assert.Equal(t, tcr.Listing{}, keeper.GetListing(ctx, "Unique registry listing"), "Outdated listing was not deleted")
+	assert.False(t, keeper.ProposalQueueContains(ctx, "Unique registry listing"), "Outdated ballot still queued")
 
 	// Check that challenger is refunded
 	coins := accountKeeper.GetCoins(ctx, challenger)
@@ -94,12 +97,8 @@ func TestActivate(t *testing.T) {
 
 	assert.Equal(t, sdk.CodeType(115), err.Code(), err.Error())
 
+	// Test valid activation with more than posted bond
 	err = keeper.ActivateBallot(ctx, accountKeeper, addr, challenger, "Unique registry listing", 10, 10, 100, 200)
-
-	assert.Equal(t, sdk.CodeType(115), err.Code(), err.Error())
-
-	// Test valid activation
-	err = keeper.ActivateBallot(ctx, accountKeeper, addr, challenger, "Unique registry listing", 10, 10, 100, 150)
 	if err != nil {
 		fmt.Println(err.Error())
 	}
@@ -107,10 +106,15 @@ func TestActivate(t *testing.T) {
 	ballot := keeper.GetBallot(ctx, "Unique registry listing")
 
 	assert.Equal(t, true, ballot.Active, "Ballot not activated")
+	assert.Equal(t, int64(150), ballot.Bond, "Bond changed by challenge")
+
+	// Check that challenger is refunded the excess bond
+	coins = accountKeeper.GetCoins(ctx, challenger)
+	assert.Equal(t, int64(150), coins.AmountOf("RegistryCoin"), "Challenger did not get refunded the excess bond")
 }
 
 func TestCommit(t *testing.T) {
-	ms, listKey, ballotKey, _ := SetupMultiStore()
+	ms, listKey, ballotKey, _, _ := SetupMultiStore()
 	cdc := MakeCodec()
 
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
@@ -120,15 +124,44 @@ func TestCommit(t *testing.T) {
 	addr := utils.GenerateAddress()
 	keeper.AddBallot(ctx, "Unique registry listing", addr, 5, 50)
 
-	keeper.CommitBallot(ctx, addr, "Unique registry listing", []byte("my commitment"))
+	keeper.CommitBallot(ctx, addr, "Unique registry listing", tcr.NewCommitment([]byte("my commitment"), 10))
 
 	commitment := keeper.GetCommitment(ctx, addr, "Unique registry listing")
 
-	assert.Equal(t, []byte("my commitment"), commitment, "Commitment not added to ballotStore correctly")
+	assert.Equal(t, tcr.NewCommitment([]byte("my commitment"), 10), commitment, "Commitment not added to ballotStore correctly")
+}
+
+func TestPurgeCommitments(t *testing.T) {
+	ms, listKey, ballotKey, accountKey, _ := SetupMultiStore()
+	cdc := MakeCodec()
+
+	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
+	keeper := NewBallotKeeper(listKey, ballotKey, cdc)
+
+	accountMapper := auth.NewAccountMapper(cdc, accountKey, &auth.BaseAccount{})
+	accountKeeper := bank.NewKeeper(accountMapper)
+
+	addr := utils.GenerateAddress()
+	keeper.AddBallot(ctx, "foo", addr, 5, 100)
+	keeper.CommitBallot(ctx, addr, "foo", tcr.NewCommitment([]byte("my commitment"), 10))
+
+	// Ballots named after the commitments of foo, one as long as a commitment key
+	colliding := []string{"foocommits", "foocommits" + "12345678901234567890"}
+	for _, identifier := range colliding {
+		keeper.AddBallot(ctx, identifier, addr, 5, 100)
+	}
+
+	keeper.PurgeCommitments(ctx, accountKeeper, "foo", 0.5)
+
+	assert.Equal(t, tcr.Commitment{}, keeper.GetCommitment(ctx, addr, "foo"), "Commitment not purged")
+	assert.Equal(t, int64(5), accountKeeper.GetCoins(ctx, addr).AmountOf("RegistryCoin"), "Stake not refunded less penalty")
+	for _, identifier := range colliding {
+		assert.Equal(t, identifier, keeper.GetBallot(ctx, identifier).Identifier, "Colliding ballot purged")
+	}
 }
 
 func TestVote(t *testing.T) {
-	ms, listKey, ballotKey, _ := SetupMultiStore()
+	ms, listKey, ballotKey, _, _ := SetupMultiStore()
 	cdc := MakeCodec()
 
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
@@ -150,7 +183,7 @@ func TestVote(t *testing.T) {
 }
 
 func TestAddDeleteList(t *testing.T) {
-	ms, listKey, ballotKey, _ := SetupMultiStore()
+	ms, listKey, ballotKey, _, _ := SetupMultiStore()
 	cdc := MakeCodec()
 
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
@@ -179,42 +212,40 @@ func TestAddDeleteList(t *testing.T) {
 // Test CandidateQueue
 
 func TestQueue(t *testing.T) {
-	ms, listKey, ballotKey, _ := SetupMultiStore()
+	ms, listKey, ballotKey, _, _ := SetupMultiStore()
 	cdc := MakeCodec()
 
 	ctx := sdk.NewContext(ms, abci.Header{}, false, nil, log.NewNopLogger())
-	ctx.WithBlockHeight(10)
 	keeper := NewBallotKeeper(listKey, ballotKey, cdc)
 
-	assert.Equal(t, tcr.PriorityQueue{}, keeper.getCandidateQueue(ctx), "Incorrect behavior on init")
-
-	candidateQueue := tcr.PriorityQueue{}
-	item := tcr.Item{Value: "a", Priority: 10}
-	heap.Push(&candidateQueue, &item)
-	keeper.setCandidateQueue(ctx, candidateQueue)
-
-	item2 := tcr.Item{Value: "b", Priority: 5}
-	heap.Push(&candidateQueue, &item2)
-	keeper.setCandidateQueue(ctx, candidateQueue)
-
-	queue := keeper.getCandidateQueue(ctx)
-	assert.Equal(t, item2, queue.Peek(), "Peek does not work")
-
-	assert.Equal(t, item2.Value, heap.Pop(&queue).(*tcr.Item).Value, "Pop does not work")
-
-	assert.Equal(t, item.Value, heap.Pop(&queue).(*tcr.Item).Value, "Pop does not work")
-
-	queue = keeper.getCandidateQueue(ctx)
-	queue.Update("b", 12)
-
-	keeper.setCandidateQueue(ctx, queue)
-
-	queue = keeper.getCandidateQueue(ctx)
-
-	assert.Equal(t, item.Value, heap.Pop(&queue).(*tcr.Item).Value, "Pop does not work")
-
-	assert.Equal(t, item2.Value, heap.Pop(&queue).(*tcr.Item).Value, "Pop does not work")
-
-} 
-
+	assert.Equal(t, tcr.Ballot{}, keeper.ProposalQueueHead(ctx), "Incorrect behavior on init")
+	assert.Equal(t, []tcr.QueueItem{}, keeper.ProposalQueueItems(ctx), "Incorrect behavior on init")
 
+	addr := utils.GenerateAddress()
+	keeper.AddBallot(ctx, "a", addr, 10, 50)
+	keeper.AddBallot(ctx, "b", addr, 5, 50)
+	keeper.AddBallot(ctx, "c", addr, 256, 50)
+	keeper.ProposalQueuePush(ctx, "a", 10)
+	keeper.ProposalQueuePush(ctx, "c", 256)
+	keeper.ProposalQueuePush(ctx, "b", 5)
+
+	assert.Equal(t, "b", keeper.ProposalQueueHead(ctx).Identifier, "Head does not work")
+	assert.True(t, keeper.ProposalQueueContains(ctx, "a"), "Contains does not work")
+	assert.Equal(t, 256, keeper.ProposalQueueGetPriority(ctx, "c"), "Priority does not work")
+
+	// Candidates are ordered by end height and then identifier
+	keeper.ProposalQueueUpdate(ctx, "b", 10)
+	expected := []tcr.QueueItem{{"a", 10}, {"b", 10}, {"c", 256}}
+	assert.Equal(t, expected, keeper.ProposalQueueItems(ctx), "Update does not work")
+	assert.Equal(t, expected[:2], keeper.ProposalQueueMatured(ctx, 255), "Matured does not work")
+
+	assert.NotNil(t, keeper.ProposalQueueUpdate(ctx, "d", 10), "Updated candidate not in queue")
+
+	assert.Equal(t, "a", keeper.ProposalQueuePop(ctx).Identifier, "Pop does not work")
+	assert.False(t, keeper.ProposalQueueContains(ctx, "a"), "Popped candidate still in queue")
+	assert.Equal(t, -1, keeper.ProposalQueueGetPriority(ctx, "a"), "Popped candidate still in queue")
+
+	keeper.ProposalQueueRemove(ctx, "c")
+	assert.Equal(t, "b", keeper.ProposalQueuePop(ctx).Identifier, "Pop does not work")
+	assert.Equal(t, tcr.Ballot{}, keeper.ProposalQueuePop(ctx), "Pop on empty queue does not work")
+}
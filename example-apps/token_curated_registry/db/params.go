@@ -0,0 +1,45 @@
+package db
+
+import (
+	tcr "github.com/cosmos/cosmos-academy/example-apps/token_curated_registry/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/tendermint/go-amino"
+)
+
+// ParamsKeeper persists the registry params set in genesis
+type ParamsKeeper struct {
+	ParamsKey sdk.StoreKey
+
+	Cdc *amino.Codec
+}
+
+func NewParamsKeeper(paramsKey sdk.StoreKey, _cdc *amino.Codec) ParamsKeeper {
+	return ParamsKeeper{
+		ParamsKey: paramsKey,
+		Cdc:       _cdc,
+	}
+}
+
+// GetParams returns the registry params. Panics if they were never set.
+func (pk ParamsKeeper) GetParams(ctx sdk.Context) tcr.Params {
+	store := ctx.KVStore(pk.ParamsKey)
+	bz := store.Get([]byte("params"))
+	if bz == nil {
+		panic("Registry params have not been set")
+	}
+	params := tcr.Params{}
+	err := pk.Cdc.UnmarshalBinary(bz, &params)
+	if err != nil {
+		panic(err)
+	}
+	return params
+}
+
+func (pk ParamsKeeper) SetParams(ctx sdk.Context, params tcr.Params) {
+	store := ctx.KVStore(pk.ParamsKey)
+	bz, err := pk.Cdc.MarshalBinary(params)
+	if err != nil {
+		panic(err)
+	}
+	store.Set([]byte("params"), bz)
+}
@@ -10,18 +10,20 @@ import (
 	dbm "github.com/tendermint/tmlibs/db"
 )
 
-func SetupMultiStore() (sdk.MultiStore, *sdk.KVStoreKey, *sdk.KVStoreKey, *sdk.KVStoreKey) {
+func SetupMultiStore() (sdk.MultiStore, *sdk.KVStoreKey, *sdk.KVStoreKey, *sdk.KVStoreKey, *sdk.KVStoreKey) {
 	db := dbm.NewMemDB()
 	listKey := sdk.NewKVStoreKey("ListKey")
 	ballotKey := sdk.NewKVStoreKey("BallotKey")
 	accountKey := sdk.NewKVStoreKey("AccountKey")
+	paramsKey := sdk.NewKVStoreKey("ParamsKey")
 	ms := store.NewCommitMultiStore(db)
 	ms.MountStoreWithDB(listKey, sdk.StoreTypeIAVL, db)
 	ms.MountStoreWithDB(ballotKey, sdk.StoreTypeIAVL, db)
 	ms.MountStoreWithDB(accountKey, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(paramsKey, sdk.StoreTypeIAVL, db)
 
 	ms.LoadLatestVersion()
-	return ms, listKey, ballotKey, accountKey
+	return ms, listKey, ballotKey, accountKey, paramsKey
 }
 
 func MakeCodec() *amino.Codec {
@@ -13,6 +13,8 @@ const (
 	CodeInvalidBallot       sdk.CodeType = 103
 	CodeInvalidPhase        sdk.CodeType = 104
 	CodeInvalidVote         sdk.CodeType = 105
+	CodeInvalidParams       sdk.CodeType = 106
+	CodeInvalidGenesis      sdk.CodeType = 108
 )
 
 func codeToDefaultMsg(code sdk.CodeType) string {
@@ -40,4 +42,12 @@ func ErrInvalidPhase(codespace sdk.CodespaceType, msg string) sdk.Error {
 
 func ErrInvalidVote(codespace sdk.CodespaceType, msg string) sdk.Error {
 	return sdk.NewError(codespace, CodeInvalidVote, msg)
+}
+
+func ErrInvalidParams(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidParams, msg)
+}
+
+func ErrInvalidGenesis(codespace sdk.CodespaceType, msg string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidGenesis, msg)
 }
\ No newline at end of file
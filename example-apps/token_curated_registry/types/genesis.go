@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
@@ -8,6 +10,98 @@ import (
 
 type GenesisState struct {
 	Accounts []*GenesisAccount `json:"accounts"`
+	Params   Params            `json:"params"`
+	Registry RegistryState     `json:"registry"`
+}
+
+// RegistryState is everything in the listing and ballot stores
+type RegistryState struct {
+	Listings       []Listing           `json:"listings"`
+	Ballots        []Ballot            `json:"ballots"`
+	Commitments    []GenesisCommitment `json:"commitments"`
+	Votes          []GenesisVote       `json:"votes"`
+	CandidateQueue []QueueItem         `json:"candidate_queue"`
+}
+
+// GenesisCommitment is a commitment not revealed yet
+type GenesisCommitment struct {
+	Identifier string      `json:"identifier"`
+	Owner      sdk.Address `json:"owner"`
+	Commitment []byte      `json:"commitment"`
+	Stake      int64       `json:"stake"`
+}
+
+// GenesisVote is a revealed vote on a ballot not finalized yet
+type GenesisVote struct {
+	Identifier string      `json:"identifier"`
+	Owner      sdk.Address `json:"owner"`
+	Vote       Vote        `json:"vote"`
+}
+
+// Validate checks that the registry state is consistent: listings, commitments, votes and
+// queued candidates have a ballot, and the candidate queue holds exactly the ballots awaiting
+// finalization at their end height: every challenged ballot and every ballot not listed yet.
+func (rs RegistryState) Validate() sdk.Error {
+	ballots := make(map[string]Ballot)
+	for _, ballot := range rs.Ballots {
+		if IsReservedIdentifier(ballot.Identifier) {
+			return ErrInvalidGenesis(DefaultCodespace, "Ballot has a reserved identifier")
+		}
+		if _, ok := ballots[ballot.Identifier]; ok {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Duplicate ballot %s", ballot.Identifier))
+		}
+		ballots[ballot.Identifier] = ballot
+	}
+
+	listings := make(map[string]bool)
+	for _, listing := range rs.Listings {
+		if _, ok := ballots[listing.Identifier]; !ok {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Listing %s has no ballot", listing.Identifier))
+		}
+		if listings[listing.Identifier] {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Duplicate listing %s", listing.Identifier))
+		}
+		listings[listing.Identifier] = true
+	}
+
+	for _, commitment := range rs.Commitments {
+		if _, ok := ballots[commitment.Identifier]; !ok || len(commitment.Owner) == 0 {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Commitment on %s has no ballot or owner", commitment.Identifier))
+		}
+		if commitment.Stake < 0 {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Commitment on %s has a negative stake", commitment.Identifier))
+		}
+	}
+	for _, vote := range rs.Votes {
+		if _, ok := ballots[vote.Identifier]; !ok || len(vote.Owner) == 0 {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Vote on %s has no ballot or owner", vote.Identifier))
+		}
+	}
+
+	queued := make(map[string]bool)
+	for _, item := range rs.CandidateQueue {
+		ballot, ok := ballots[item.Identifier]
+		if !ok {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Queued candidate %s has no ballot", item.Identifier))
+		}
+		if queued[item.Identifier] {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Candidate %s is queued twice", item.Identifier))
+		}
+		if item.EndHeight != ballot.EndApplyBlockStamp {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Candidate %s is queued at %d but its ballot ends at %d",
+				item.Identifier, item.EndHeight, ballot.EndApplyBlockStamp))
+		}
+		queued[item.Identifier] = true
+	}
+	for _, ballot := range rs.Ballots {
+		if ballot.Active && !queued[ballot.Identifier] {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Challenged ballot %s is not queued", ballot.Identifier))
+		}
+		if !listings[ballot.Identifier] && !queued[ballot.Identifier] {
+			return ErrInvalidGenesis(DefaultCodespace, fmt.Sprintf("Candidate %s is neither listed nor queued", ballot.Identifier))
+		}
+	}
+	return nil
 }
 
 // GenesisAccount doesn't need pubkey or sequence
@@ -103,17 +103,20 @@ func (msg ChallengeMsg) GetSigners() []sdk.Address {
 // ===================================================================================================================================
 
 // CommitMsg is used to make a commitment during commit phase on an active challenge to a specific listing identified by Identifier.
+// Bond is the voting power. It is locked with the commitment and unlocked once the ballot is finalized.
 type CommitMsg struct {
 	Owner      sdk.Address
 	Identifier string
 	Commitment []byte
+	Bond       sdk.Coin
 }
 
-func NewCommitMsg(owner sdk.Address, identifier string, commitment []byte) CommitMsg {
+func NewCommitMsg(owner sdk.Address, identifier string, commitment []byte, bond sdk.Coin) CommitMsg {
 	return CommitMsg{
 		Owner:      owner,
 		Identifier: identifier,
 		Commitment: commitment,
+		Bond:       bond,
 	}
 }
 
@@ -125,6 +128,9 @@ func (msg CommitMsg) ValidateBasic() sdk.Error {
 	if msg.Owner == nil {
 		return sdk.ErrInvalidAddress("Must provide owner address")
 	}
+	if msg.Bond.Amount <= 0 || msg.Bond.Denom != TokenName {
+		return ErrInvalidDeposit(2, "Must provide Bond in RegistryCoin")
+	}
 	return nil
 }
 
@@ -143,21 +149,20 @@ func (msg CommitMsg) GetSigners() []sdk.Address {
 // ===================================================================================================================================
 
 // RevealMsg is to reveal vote during reveal phase on active challenge to listing identified by Identifier.
+// The vote counts with the bond locked by the commitment.
 type RevealMsg struct {
 	Owner      sdk.Address
 	Identifier string
 	Vote       bool
 	Nonce      []byte
-	Bond       sdk.Coin
 }
 
-func NewRevealMsg(owner sdk.Address, identifier string, vote bool, nonce []byte, bond sdk.Coin) RevealMsg {
+func NewRevealMsg(owner sdk.Address, identifier string, vote bool, nonce []byte) RevealMsg {
 	return RevealMsg{
 		Owner:      owner,
 		Identifier: identifier,
 		Vote:       vote,
 		Nonce:      nonce,
-		Bond:       bond,
 	}
 }
 
@@ -169,9 +174,6 @@ func (msg RevealMsg) ValidateBasic() sdk.Error {
 	if msg.Owner == nil {
 		return sdk.ErrInvalidAddress("Must provide Owner address")
 	}
-	if msg.Bond.Amount <= 0 || msg.Bond.Denom != TokenName {
-		return ErrInvalidDeposit(2, "Must provide Bond in RegistryCoin")
-	}
 	return nil
 }
 
@@ -187,12 +189,151 @@ func (msg RevealMsg) GetSigners() []sdk.Address {
 	return []sdk.Address{msg.Owner}
 }
 
+// ===================================================================================================================================
+
+// ExitListingMsg is used by the owner of an unchallenged listing to remove it from the registry and get the deposit back.
+type ExitListingMsg struct {
+	Owner      sdk.Address
+	Identifier string
+}
+
+func NewExitListingMsg(owner sdk.Address, identifier string) ExitListingMsg {
+	return ExitListingMsg{
+		Owner:      owner,
+		Identifier: identifier,
+	}
+}
+
+func (msg ExitListingMsg) Type() string {
+	return "ExitListing"
+}
+
+func (msg ExitListingMsg) ValidateBasic() sdk.Error {
+	if msg.Owner == nil {
+		return sdk.ErrInvalidAddress("Must provide Owner address")
+	}
+	if msg.Identifier == "" {
+		return ErrInvalidBallot(DefaultCodespace, "Must provide listing identifier")
+	}
+	return nil
+}
+
+func (msg ExitListingMsg) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (msg ExitListingMsg) GetSigners() []sdk.Address {
+	return []sdk.Address{msg.Owner}
+}
+
+// ===================================================================================================================================
+
+// DepositMsg is used by the owner of an unchallenged listing to add Amount to its deposit.
+// A larger deposit keeps the listing above a raised minimum deposit and raises the bond a challenger must match.
+type DepositMsg struct {
+	Owner      sdk.Address
+	Identifier string
+	Amount     sdk.Coin
+}
+
+func NewDepositMsg(owner sdk.Address, identifier string, amount sdk.Coin) DepositMsg {
+	return DepositMsg{
+		Owner:      owner,
+		Identifier: identifier,
+		Amount:     amount,
+	}
+}
+
+func (msg DepositMsg) Type() string {
+	return "Deposit"
+}
+
+func (msg DepositMsg) ValidateBasic() sdk.Error {
+	if msg.Owner == nil {
+		return sdk.ErrInvalidAddress("Must provide Owner address")
+	}
+	if msg.Identifier == "" {
+		return ErrInvalidBallot(DefaultCodespace, "Must provide listing identifier")
+	}
+	if !msg.Amount.IsPositive() || msg.Amount.Denom != TokenName {
+		return ErrInvalidDeposit(DefaultCodespace, "Must provide Amount in RegistryCoin")
+	}
+	return nil
+}
+
+func (msg DepositMsg) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (msg DepositMsg) GetSigners() []sdk.Address {
+	return []sdk.Address{msg.Owner}
+}
+
+// ===================================================================================================================================
+
+// WithdrawMsg is used by the owner of an unchallenged listing to take Amount out of its deposit.
+// The deposit left behind must still be at least the current minimum deposit.
+type WithdrawMsg struct {
+	Owner      sdk.Address
+	Identifier string
+	Amount     sdk.Coin
+}
+
+func NewWithdrawMsg(owner sdk.Address, identifier string, amount sdk.Coin) WithdrawMsg {
+	return WithdrawMsg{
+		Owner:      owner,
+		Identifier: identifier,
+		Amount:     amount,
+	}
+}
+
+func (msg WithdrawMsg) Type() string {
+	return "Withdraw"
+}
+
+func (msg WithdrawMsg) ValidateBasic() sdk.Error {
+	if msg.Owner == nil {
+		return sdk.ErrInvalidAddress("Must provide Owner address")
+	}
+	if msg.Identifier == "" {
+		return ErrInvalidBallot(DefaultCodespace, "Must provide listing identifier")
+	}
+	if !msg.Amount.IsPositive() || msg.Amount.Denom != TokenName {
+		return ErrInvalidDeposit(DefaultCodespace, "Must provide Amount in RegistryCoin")
+	}
+	return nil
+}
+
+func (msg WithdrawMsg) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func (msg WithdrawMsg) GetSigners() []sdk.Address {
+	return []sdk.Address{msg.Owner}
+}
+
 func RegisterAmino(cdc *amino.Codec) {
 	cdc.RegisterConcrete(DeclareCandidacyMsg{}, "types/DeclareCandidacyMsg", nil)
 	cdc.RegisterConcrete(ChallengeMsg{}, "types/ChallengeMsg", nil)
 	cdc.RegisterConcrete(CommitMsg{}, "types/CommintMsg", nil)
 	cdc.RegisterConcrete(RevealMsg{}, "types/RevealMsg", nil)
+	cdc.RegisterConcrete(ExitListingMsg{}, "types/ExitListingMsg", nil)
+	cdc.RegisterConcrete(DepositMsg{}, "types/DepositMsg", nil)
+	cdc.RegisterConcrete(WithdrawMsg{}, "types/WithdrawMsg", nil)
 	cdc.RegisterConcrete(Listing{}, "types/Listing", nil)
 	cdc.RegisterConcrete(Vote{}, "types/Vote", nil)
+	cdc.RegisterConcrete(Commitment{}, "types/Commitment", nil)
 	cdc.RegisterConcrete(Ballot{}, "types/Ballot", nil)
 }
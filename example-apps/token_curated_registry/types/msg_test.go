@@ -36,3 +36,22 @@ func TestInvalidAmount(t *testing.T) {
 
 	assert.Equal(t, sdk.CodeType(101), err.Code(), err.Error())
 }
+
+func TestDepositAndWithdrawMsg(t *testing.T) {
+	candidacy := GenerateCandidacyMsg()
+	amount := sdk.Coin{TokenName, 50}
+
+	deposit := NewDepositMsg(candidacy.Owner, candidacy.Identifier, amount)
+	assert.Nil(t, deposit.ValidateBasic())
+
+	withdraw := NewWithdrawMsg(candidacy.Owner, candidacy.Identifier, amount)
+	assert.Nil(t, withdraw.ValidateBasic())
+
+	deposit.Amount.Denom = "FakeCoin"
+	err := deposit.ValidateBasic()
+	assert.Equal(t, sdk.CodeType(101), err.Code(), err.Error())
+
+	withdraw.Amount.Amount = 0
+	err = withdraw.ValidateBasic()
+	assert.Equal(t, sdk.CodeType(101), err.Code(), err.Error())
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Params of the registry. They are set in genesis and must be the same on every node.
+// CONTRACT: ApplyStage >= CommitStage + RevealStage
+type Params struct {
+	MinDeposit      int64   `json:"min_deposit"`
+	ApplyStage      int64   `json:"apply_stage"`
+	CommitStage     int64   `json:"commit_stage"`
+	RevealStage     int64   `json:"reveal_stage"`
+	DispensationPct float64 `json:"dispensation_pct" amino:"unsafe"`
+	Quorum          float64 `json:"quorum" amino:"unsafe"`
+
+	// Ballots finalized per block at most. Matured ballots beyond it wait for the next blocks.
+	MaxResolvePerBlock int64 `json:"max_resolve_per_block"`
+
+	// Least RegistryCoin locked with a commitment as voting power. A commitment still
+	// unrevealed when its ballot is finalized loses UnrevealedPenaltyPct of its stake.
+	MinCommitStake       int64   `json:"min_commit_stake"`
+	UnrevealedPenaltyPct float64 `json:"unrevealed_penalty_pct" amino:"unsafe"`
+}
+
+func DefaultParams() Params {
+	return Params{
+		MinDeposit:      100,
+		ApplyStage:      100,
+		CommitStage:     50,
+		RevealStage:     50,
+		DispensationPct: 0.5,
+		Quorum:          0.5,
+
+		MaxResolvePerBlock: 100,
+
+		MinCommitStake:       10,
+		UnrevealedPenaltyPct: 0.1,
+	}
+}
+
+// Validate checks the params against the contract of the registry
+func (p Params) Validate() sdk.Error {
+	if p.MinDeposit <= 0 {
+		return ErrInvalidParams(DefaultCodespace, "Minimum deposit must be positive")
+	}
+	if p.ApplyStage <= 0 || p.CommitStage <= 0 || p.RevealStage <= 0 {
+		return ErrInvalidParams(DefaultCodespace, "Stages must be positive")
+	}
+	if p.ApplyStage < p.CommitStage+p.RevealStage {
+		return ErrInvalidParams(DefaultCodespace, "Apply stage must be at least commit stage + reveal stage")
+	}
+	if p.MaxResolvePerBlock <= 0 {
+		return ErrInvalidParams(DefaultCodespace, "Max resolved ballots per block must be positive")
+	}
+	if p.DispensationPct < 0 || p.DispensationPct > 1 {
+		return ErrInvalidParams(DefaultCodespace, "Dispensation pct must be between 0 and 1")
+	}
+	if p.Quorum < 0 || p.Quorum > 1 {
+		return ErrInvalidParams(DefaultCodespace, "Quorum must be between 0 and 1")
+	}
+	if p.MinCommitStake < 0 {
+		return ErrInvalidParams(DefaultCodespace, "Minimum commit stake cannot be negative")
+	}
+	if p.UnrevealedPenaltyPct < 0 || p.UnrevealedPenaltyPct > 1 {
+		return ErrInvalidParams(DefaultCodespace, "Unrevealed penalty pct must be between 0 and 1")
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package types
+
+// Custom query paths of the registry. Arguments are passed in the query data.
+const (
+	QueryListing  = "/custom/registry/listing"  // data: identifier
+	QueryListings = "/custom/registry/listings" // data: JSON ListingsParams
+	QueryBallot   = "/custom/registry/ballot"   // data: identifier
+	QueryQueue    = "/custom/registry/queue"
+)
+
+// Default and maximum number of listings returned by QueryListings
+const (
+	DefaultListingsLimit = 100
+	MaxListingsLimit     = 1000
+)
+
+// ListingsParams selects a page of listings, ordered by identifier
+type ListingsParams struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+// BallotStatus is a ballot with the phase it is in at the queried height
+type BallotStatus struct {
+	Ballot
+	Phase  string `json:"phase"`
+	Height int64  `json:"height"`
+}
+
+// QueueItem is a candidate queue entry, resolved at EndHeight
+type QueueItem struct {
+	Identifier string `json:"identifier"`
+	EndHeight  int64  `json:"end_height"`
+}
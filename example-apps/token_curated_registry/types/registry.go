@@ -1,6 +1,8 @@
 package types
 
 import (
+	"strings"
+
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
@@ -29,6 +31,19 @@ func NewVote(choice bool, power int64) Vote {
 	}
 }
 
+// Commitment made during commit phase, with the voting stake locked until the ballot is finalized
+type Commitment struct {
+	Hash  []byte
+	Stake int64
+}
+
+func NewCommitment(hash []byte, stake int64) Commitment {
+	return Commitment{
+		Hash:  hash,
+		Stake: stake,
+	}
+}
+
 type Ballot struct {
 	Identifier          string
 	Details             string
@@ -51,4 +66,59 @@ func NewBallot(identifier string, details string, owner sdk.Address, endApplyBlo
 	}
 }
 
+// Prefixes of the candidate queue in the ballot store. Identifiers starting with
+// candidateQueue are reserved.
+const (
+	QueuePrefix      = "candidateQueue:"
+	QueueIndexPrefix = "candidateQueueIndex:"
+)
+
+// KeySeparator ends the identifier in the commitment and vote keys of a ballot,
+// {identifier}{KeySeparator}commits{owner} and {identifier}{KeySeparator}votes{owner}.
+// Identifiers can't contain it, so these keys never collide with a ballot or with the
+// keys of another identifier.
+const KeySeparator = "\x00"
+
+// CommitmentPrefix is the prefix of the commitment keys of a ballot
+func CommitmentPrefix(identifier string) []byte {
+	return []byte(identifier + KeySeparator + "commits")
+}
+
+// VotePrefix is the prefix of the vote keys of a ballot
+func VotePrefix(identifier string) []byte {
+	return []byte(identifier + KeySeparator + "votes")
+}
+
+// IsReservedIdentifier reports whether a ballot identifier would collide with the queue,
+// commitment or vote keys
+func IsReservedIdentifier(identifier string) bool {
+	return identifier == "" || strings.HasPrefix(identifier, "candidateQueue") ||
+		strings.Contains(identifier, KeySeparator)
+}
+
+// Phases of a ballot, as reported by Phase
+const (
+	PhaseApply    = "apply"
+	PhaseCommit   = "commit"
+	PhaseReveal   = "reveal"
+	PhaseResolved = "resolved"
+)
 
+// Phase returns the phase the ballot is in at the given height. A ballot is in
+// the apply phase until EndApplyBlockStamp unless it is challenged, in which case
+// commits are accepted until EndCommitBlockStamp and reveals until EndApplyBlockStamp.
+func (ballot Ballot) Phase(height int64) string {
+	if !ballot.Active {
+		if height < ballot.EndApplyBlockStamp {
+			return PhaseApply
+		}
+		return PhaseResolved
+	}
+	if height <= ballot.EndCommitBlockStamp {
+		return PhaseCommit
+	}
+	if height <= ballot.EndApplyBlockStamp {
+		return PhaseReveal
+	}
+	return PhaseResolved
+}